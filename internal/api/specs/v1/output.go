@@ -0,0 +1,21 @@
+package v1
+
+// Output configures where a kpm file is written and how its image is tagged.
+// File and Tags are Go templates over the fields of the built image:
+// .ID, .Name, .Package, .Version, .Channel, .Channels, and .Digest. They are
+// rendered after the spec file itself, so in templated spec files they must
+// be escaped, e.g. '{{ "{{ .Version }}" }}'.
+type Output struct {
+	// Directory is the directory the kpm file is written to, relative to
+	// the spec file. Defaults to the current directory.
+	Directory string `json:"directory,omitempty"`
+
+	// File is the name of the kpm file, relative to Directory. Defaults to
+	// "{{ .ID }}.kpm".
+	File string `json:"file,omitempty"`
+
+	// Tags are additional tags applied to the image in the kpm file. A tag
+	// template may render to several comma-separated tags, e.g.
+	// '{{ join "," .Channels }}'.
+	Tags []string `json:"tags,omitempty"`
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -8,19 +10,160 @@ const (
 	KindRegistryV1 = "RegistryV1"
 
 	RegistryV1SourceTypeBundleDirectory = "BundleDirectory"
+	RegistryV1SourceTypeImage           = "Image"
+	RegistryV1SourceTypeArchive         = "Archive"
+	RegistryV1SourceTypeGit             = "Git"
+	RegistryV1SourceTypeKustomize       = "Kustomize"
+
+	RegistryV1OverrideModeMerge   = "Merge"
+	RegistryV1OverrideModeReplace = "Replace"
+
+	RegistryV1PatchTypeStrategicMerge = "StrategicMerge"
+	RegistryV1PatchTypeJSON6902       = "JSON6902"
 )
 
 type RegistryV1 struct {
 	metav1.TypeMeta `json:",inline"`
 
 	Source RegistryV1Source `json:"source"`
+	Output *Output          `json:"output,omitempty"`
+
+	// PopulateRelatedImages completes the CSV's spec.relatedImages with every
+	// image referenced by its install strategy deployments: container and
+	// init container images, and the values of RELATED_IMAGE_* env vars.
+	PopulateRelatedImages bool `json:"populateRelatedImages,omitempty"`
+
+	// Annotations, Properties and Dependencies override the bundle's
+	// metadata/annotations.yaml, properties.yaml and dependencies.yaml,
+	// e.g. to change its channels without a separate bundle directory. They
+	// are applied before the bundle is validated.
+	Annotations  *RegistryV1AnnotationsOverride  `json:"annotations,omitempty"`
+	Properties   *RegistryV1PropertiesOverride   `json:"properties,omitempty"`
+	Dependencies *RegistryV1DependenciesOverride `json:"dependencies,omitempty"`
+
+	// Patches are applied, in order, to the bundle's manifests before they
+	// are validated, e.g. to adjust resource limits, labels or the CSV's
+	// description per distribution.
+	Patches []RegistryV1Patch `json:"patches,omitempty"`
+}
+
+// RegistryV1Patch is a patch of the bundle manifests that match its target.
+type RegistryV1Patch struct {
+	// Type is StrategicMerge or JSON6902.
+	Type   string                `json:"type"`
+	Target RegistryV1PatchTarget `json:"target"`
+	// Patch is the patch document in YAML or JSON: a partial object for
+	// strategic merge patches, or a list of operations for JSON 6902
+	// patches.
+	Patch string `json:"patch"`
+}
+
+// RegistryV1PatchTarget selects manifests by the group, kind and name of
+// their object, as patched by the patches before it. Empty fields match any
+// value.
+type RegistryV1PatchTarget struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// RegistryV1AnnotationsOverride overrides the annotations of a bundle.
+type RegistryV1AnnotationsOverride struct {
+	// Mode is Merge (the default), which sets the given annotations and
+	// keeps the others, or Replace, which replaces all annotations.
+	Mode   string            `json:"mode,omitempty"`
+	Values map[string]string `json:"values"`
+}
+
+// RegistryV1PropertiesOverride overrides the properties of a bundle.
+type RegistryV1PropertiesOverride struct {
+	// Mode is Merge (the default), which adds the given properties unless
+	// the bundle already has them, or Replace, which replaces all
+	// properties.
+	Mode   string               `json:"mode,omitempty"`
+	Values []RegistryV1Property `json:"values"`
+}
+
+// RegistryV1DependenciesOverride overrides the dependencies of a bundle.
+type RegistryV1DependenciesOverride struct {
+	// Mode is Merge (the default) or Replace, like for properties.
+	Mode   string               `json:"mode,omitempty"`
+	Values []RegistryV1Property `json:"values"`
+}
+
+// RegistryV1Property is a property or dependency of a bundle, e.g. one of
+// type olm.package.required or olm.gvk.
+type RegistryV1Property struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
 }
 
 type RegistryV1Source struct {
 	SourceType      string                           `json:"sourceType"`
 	BundleDirectory *RegistryV1BundleDirectorySource `json:"bundleDirectory,omitempty"`
+	Image           *RegistryV1ImageSource           `json:"image,omitempty"`
+	Archive         *RegistryV1ArchiveSource         `json:"archive,omitempty"`
+	Git             *RegistryV1GitSource             `json:"git,omitempty"`
+	Kustomize       *RegistryV1KustomizeSource       `json:"kustomize,omitempty"`
 }
 
 type RegistryV1BundleDirectorySource struct {
 	Path string `json:"path"`
 }
+
+// RegistryV1ImageSource is an existing bundle image.
+type RegistryV1ImageSource struct {
+	// Reference is the bundle image: either an image registry reference
+	// (e.g. quay.io/org/bundle:v1.2.3), oci-layout:<directory>, or
+	// oci-archive:<file> (e.g. a kpm file). OCI layout and archive
+	// references may end in :<tag> or @<digest> to choose a manifest; by
+	// default the layout must have a single tagged manifest.
+	Reference string `json:"reference"`
+}
+
+// RegistryV1ArchiveSource is a .tar, .tar.gz or .zip archive of a bundle
+// directory, such as an upstream release tarball. The files in the archive
+// must not exceed 1 GiB in total.
+type RegistryV1ArchiveSource struct {
+	// Path is the local path of the archive. Exactly one of Path and URL
+	// must be set.
+	Path string `json:"path,omitempty"`
+	// URL is the HTTP(S) URL of the archive. Downloads time out after five
+	// minutes, and archives larger than 512 MiB are rejected.
+	URL string `json:"url,omitempty"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the archive. It is
+	// required for URLs, and verified for local paths if set.
+	SHA256 string `json:"sha256,omitempty"`
+	// Directory is the bundle directory within the archive, e.g. the
+	// top-level directory of a release tarball. It defaults to the root of
+	// the archive.
+	Directory string `json:"directory,omitempty"`
+}
+
+// RegistryV1GitSource is a bundle directory in a commit of a git repository.
+// The bundle is read from the commit's tree, so the repository's working
+// tree is neither used nor modified.
+type RegistryV1GitSource struct {
+	// Repository is the path of a local git repository, or a file:// URL.
+	Repository string `json:"repository"`
+	// Revision is the branch, tag or commit to build. It defaults to HEAD.
+	Revision string `json:"revision,omitempty"`
+	// Directory is the bundle directory within the repository. It defaults
+	// to the root of the repository.
+	Directory string `json:"directory,omitempty"`
+}
+
+// RegistryV1KustomizeSource is a kustomization that renders the manifests of
+// a bundle, such as an operator project's config/manifests. The bundle
+// metadata, which kustomize does not render, is generated from the package
+// and channels.
+type RegistryV1KustomizeSource struct {
+	// Directory is the directory of the kustomization.
+	Directory string `json:"directory"`
+	// Package is the name of the bundle's package.
+	Package string `json:"package"`
+	// Channels are the channels the bundle is in.
+	Channels []string `json:"channels,omitempty"`
+	// DefaultChannel is the default channel of the bundle's package.
+	DefaultChannel string `json:"defaultChannel,omitempty"`
+}
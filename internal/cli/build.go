@@ -2,51 +2,233 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
 	"github.com/operator-framework/kpm/internal/pkg/spec"
 )
 
 func Build() *cobra.Command {
-	var reportFile string
+	var (
+		reportFile   string
+		reportFormat string
+		jobs         int
+		buildOptions spec.BuildOptions
+		pinImages    pinImagesOptions
+		specOptions  specOptions
+	)
 
 	cmd := &cobra.Command{
-		Use:  "build <spec-file>",
-		Args: cobra.ExactArgs(1),
+		Use:   "build <spec-file|directory|glob>...",
+		Short: "Build kpm files from spec files",
+		Long: `Build kpm files from spec files.
+
+Each argument is a spec file, a directory whose *.kpmspec.yaml and
+*.kpmspec.star files are built, or a glob pattern matching spec files. Specs
+are built concurrently (see --jobs). A failed build does not stop the others,
+and the exit status is non-zero if any build failed.
+
+Spec files are Go templates with sprig functions, rendered with the values
+from --values and --set, which are available as .Values. For example:
+
+  source:
+    sourceType: BundleDirectory
+    bundleDirectory:
+      path: ./bundles/{{ .Values.version }}
+
+Template functions whose results depend on the current time, randomness, or
+the environment are disabled unless --allow-nondeterministic is set, so that
+builds are reproducible.
+
+Spec files named *.kpmspec.star are Starlark programs that define a specs()
+function returning one spec object or a list of spec objects, each of which
+is built. Programs can read files in the spec file's directory with glob(),
+read_file(), and read_yaml(), and the values are available as the values
+dict. For example:
+
+  def specs():
+      return [{
+          "apiVersion": "specs.kpm.io/v1alpha1",
+          "kind": "RegistryV1",
+          "source": {
+              "sourceType": "BundleDirectory",
+              "bundleDirectory": {"path": dir},
+          },
+      } for dir in glob("bundles/*")]
+
+The specs of Starlark programs may only use bundle directories, archives, and
+git repositories within the spec file's directory; image and kustomize
+sources, archive URLs, and file:// repository URLs are rejected.
+
+By default, each kpm file is written to <id>.kpm in the current directory. A
+spec can choose a different location and apply additional tags with its
+output section, which --output, --output-dir, and --tag override or extend:
+
+  output:
+    directory: ./dist
+    file: '{{ "{{ .Package }}-{{ .Version }}.kpm" }}'
+    tags: ['latest', '{{ "{{ .Channel }}" }}']
+
+The output file and tags are Go templates over .ID, .Name, .Package,
+.Version, .Channel, .Channels, and .Digest. Because YAML spec files are
+templates themselves, output templates in them must be escaped as above. Tags
+are applied to the image in addition to its version, and kpm push pushes
+them along with it.
+
+With --provenance, an in-toto statement with a SLSA provenance predicate is
+written next to each kpm file as <name>.provenance.json. Its subject is the
+image manifest, and its resolved dependencies are the spec file and every
+source file, with their sha256 digests and paths relative to the current
+directory, so that the sources can be verified offline.
+
+With --sbom, an SBOM listing the bundle, its files, its CRDs, and the images
+it references is written next to each kpm file as <name>.spdx.json or
+<name>.cdx.json. With --attach-sbom, SBOMs are attached to the image in the
+kpm file, where kpm inspect --sbom can retrieve them.
+
+With --pin-images, every image referenced by the CSV's deployments (container
+and init container images and RELATED_IMAGE_* env vars) and related images is
+resolved to a digest, and the CSV is rewritten to reference the images by
+digest before it is built, as required for disconnected installs. Images are
+resolved against their registries, or offline with --image-layout, an OCI
+image layout tagged with image references, or --image-mapping, a YAML file
+mapping image references to digests:
+
+  quay.io/org/operator:v1.2.3: sha256:...
+
+The build report lists the original and pinned reference of each image.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			cmd.SilenceUsage = true
 			cmd.SilenceErrors = true
 
-			// load kpm spec file from cli arg
-			//   TODO: evaluate/render spec file? (gotemplate, hcl, starlark, etc.)
-			specFileLoader := spec.DefaultYAML
-			specFile, err := specFileLoader.LoadSpecFile(args[0])
+			if reportFormat != spec.ReportFormatJSON && reportFormat != spec.ReportFormatYAML {
+				return fmt.Errorf("unknown report format %q", reportFormat)
+			}
+			for _, format := range buildOptions.SBOM.Formats {
+				if _, err := sbom.MediaType(format); err != nil {
+					return err
+				}
+			}
+			specFiles, err := expandSpecFiles(args)
 			if err != nil {
 				return err
 			}
-
-			// write it
-			report, err := spec.Build(ctx, specFile)
-			if err != nil {
+			if buildOptions.ImageResolver, err = pinImages.resolver(ctx, specOptions.remote); err != nil {
 				return err
 			}
 
+			// load and write them
+			results := spec.BuildAll(ctx, specFiles, specOptions.loadSpecs, jobs, buildOptions)
+			// Keep stdout machine-readable when the report is written to it.
+			out := cmd.OutOrStdout()
+			if reportFile == "-" {
+				out = cmd.ErrOrStderr()
+			}
+			for _, result := range results {
+				if result.Err() != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", result.SpecFile, result.Err())
+					continue
+				}
+				for _, warning := range result.Report.Warnings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: warning: %s\n", result.SpecFile, warning)
+				}
+				fmt.Fprintf(out, "%s written to %s (digest: %s)\n",
+					result.Report.ID,
+					result.Report.OutputFile,
+					result.Report.Descriptor.Digest,
+				)
+			}
+
 			if reportFile != "" {
-				if err := report.WriteFile(reportFile); err != nil {
+				if err := writeBuildReport(cmd, results, reportFile, reportFormat); err != nil {
 					return err
 				}
 			}
 
-			fmt.Printf("%s written to %s (digest: %s)\n",
-				report.ID,
-				report.OutputFile,
-				report.Descriptor.Digest,
-			)
+			if err := results.Err(); err != nil {
+				var failed int
+				for _, result := range results {
+					if result.Err() != nil {
+						failed++
+					}
+				}
+				return fmt.Errorf("%d of %d builds failed", failed, len(results))
+			}
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&reportFile, "report-file", "", "if specified, path to write build report")
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "if specified, path to write the build report, which lists the result of each spec, or - for stdout (the build summary then goes to stderr)")
+	cmd.Flags().StringVar(&reportFormat, "report-format", spec.ReportFormatJSON, fmt.Sprintf("build report format: one of %s or %s", spec.ReportFormatJSON, spec.ReportFormatYAML))
+	cmd.Flags().StringVarP(&buildOptions.Output.File, "output", "o", "", "path template of the kpm file, overriding the spec's output file and directory (default \"{{ .ID }}.kpm\")")
+	cmd.Flags().StringVar(&buildOptions.Output.Directory, "output-dir", "", "directory to write kpm files to, overriding the spec's output directory")
+	cmd.Flags().StringArrayVar(&buildOptions.Output.Tags, "tag", nil, "additional tag template to apply to the image, in addition to the spec's output tags (can be repeated)")
+	cmd.Flags().BoolVar(&buildOptions.Provenance.Enabled, "provenance", false, "write SLSA build provenance (an in-toto statement) next to each kpm file")
+	cmd.Flags().BoolVar(&buildOptions.Provenance.Attach, "attach-provenance", false, "also attach the build provenance to the image in the kpm file as an OCI referrer; implies --provenance")
+	cmd.Flags().StringSliceVar(&buildOptions.SBOM.Formats, "sbom", nil, fmt.Sprintf("write an SBOM in each of the given formats next to each kpm file: %s", strings.Join(sbom.Formats, ", ")))
+	cmd.Flags().BoolVar(&buildOptions.SBOM.Attach, "attach-sbom", false, "attach the SBOMs to the image in the kpm file as OCI referrers; attaches an SPDX SBOM if --sbom is not set")
+	pinImages.bindFlags(cmd)
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "maximum number of specs to build concurrently")
+	specOptions.bindFlags(cmd)
 	return cmd
 }
+
+// writeBuildReport writes the report of results, which lists the result of
+// each spec however many specs were built, to reportFile, or to the
+// command's output if reportFile is "-".
+func writeBuildReport(cmd *cobra.Command, results spec.BuildResults, reportFile string, format string) error {
+	if reportFile == "-" {
+		return results.Write(cmd.OutOrStdout(), format)
+	}
+	return results.WriteFile(reportFile, format)
+}
+
+var specFilePatterns = []string{"*.kpmspec.yaml", "*" + spec.StarlarkSpecFileSuffix}
+
+// expandSpecFiles expands directories and glob patterns in args to the spec
+// files they contain or match.
+func expandSpecFiles(args []string) ([]string, error) {
+	var specFiles []string
+	for _, arg := range args {
+		var matches []string
+		switch info, err := os.Stat(arg); {
+		case err == nil && info.IsDir():
+			for _, pattern := range specFilePatterns {
+				dirMatches, err := filepath.Glob(filepath.Join(arg, pattern))
+				if err != nil {
+					return nil, err
+				}
+				matches = append(matches, dirMatches...)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no spec files found in directory %s", arg)
+			}
+		case err == nil:
+			matches = []string{arg}
+		case strings.ContainsAny(arg, "*?["):
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q: %v", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no spec files match %s", arg)
+			}
+		default:
+			return nil, err
+		}
+		slices.Sort(matches)
+		for _, match := range matches {
+			if !slices.Contains(specFiles, match) {
+				specFiles = append(specFiles, match)
+			}
+		}
+	}
+	return specFiles, nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/spec"
+)
+
+func Test_Build_ReportToStdout(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTestBundle(t, "bundle")
+	require.NoError(t, os.WriteFile("bundle.kpmspec.yaml", []byte(`apiVersion: specs.kpm.io/v1alpha1
+kind: RegistryV1
+source:
+  sourceType: BundleDirectory
+  bundleDirectory:
+    path: ./bundle
+`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	cmd := Build()
+	cmd.SetArgs([]string{"--report-file", "-", "bundle.kpmspec.yaml"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	require.NoError(t, cmd.ExecuteContext(t.Context()))
+
+	// The report lists the result of each spec, even for a single spec.
+	var report struct {
+		Kind    string            `json:"kind"`
+		Results spec.BuildResults `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.Equal(t, spec.KindBuildReportList, report.Kind)
+	require.Len(t, report.Results, 1)
+	require.Equal(t, "bundle.kpmspec.yaml", report.Results[0].SpecFile)
+	require.Equal(t, "example.v1.2.3", report.Results[0].Report.ID)
+	require.Contains(t, stderr.String(), "example.v1.2.3 written to example.v1.2.3.kpm")
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+// loadedBundle is a bundle along with the OCI image that contains it.
+type loadedBundle struct {
+	bundle   *registryv1.Bundle
+	target   content.ReadOnlyGraphStorage
+	manifest ocispec.Descriptor
+}
+
+// loadBundle loads a registry+v1 bundle from either a bundle directory or a
+// kpm file. For bundle directories, the image is the one that would be built
+// from that directory. For kpm files, ref selects the manifest to load (see
+// ocilayout.ResolveManifest).
+func loadBundle(ctx context.Context, path string, ref string) (*loadedBundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		bundle, err := registryv1.NewBundleFSLoader(os.DirFS(path)).Load()
+		if err != nil {
+			return nil, err
+		}
+		target := memory.New()
+		desc, err := bundle.MarshalOCI(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		return &loadedBundle{bundle: bundle, target: target, manifest: desc}, nil
+	}
+
+	target, err := ocilayout.OpenArchive(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := ocilayout.ResolveManifest(ctx, target, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve bundle manifest in %q: %w", path, err)
+	}
+	bundle, err := registryv1.NewBundleImageLoader(ctx, target, desc).Load()
+	if err != nil {
+		return nil, err
+	}
+	return &loadedBundle{bundle: bundle, target: target, manifest: desc}, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+func Check() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run safety checks against bundles",
+	}
+	cmd.AddCommand(
+		checkCRDUpgrade(),
+	)
+	return cmd
+}
+
+type crdUpgradeResult struct {
+	From     string                         `json:"from"`
+	To       string                         `json:"to"`
+	Safe     bool                           `json:"safe"`
+	Findings []registryv1.CRDUpgradeFinding `json:"findings"`
+}
+
+func checkCRDUpgrade() *cobra.Command {
+	var (
+		fromRef string
+		toRef   string
+		output  outputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "crd-upgrade <old> <new>",
+		Short: "Check whether upgrading between two bundles breaks existing CRDs",
+		Long: `Check whether upgrading between two bundles breaks existing CRDs.
+
+Each bundle can be a bundle directory or a kpm file. The CRDs of the old bundle
+are compared to the CRDs of the new bundle, and changes that may break clusters
+with existing custom resources are reported, including:
+
+  - removed CRDs, and changed CRD scopes
+  - removed served or stored versions
+  - storage version changes without a migration path
+  - removed fields and newly required fields
+  - narrowed enums, changed types, and tightened validation
+
+The exit status is 0 if the upgrade is safe, 1 if breaking changes were found,
+and 2 if the check could not be run.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := output.validate(); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			from, err := loadBundle(ctx, args[0], fromRef)
+			if err != nil {
+				return &ExitError{Code: exitCodeError, Err: fmt.Errorf("failed to load %s: %w", args[0], err)}
+			}
+			to, err := loadBundle(ctx, args[1], toRef)
+			if err != nil {
+				return &ExitError{Code: exitCodeError, Err: fmt.Errorf("failed to load %s: %w", args[1], err)}
+			}
+
+			findings := registryv1.CheckCRDUpgrade(from.bundle, to.bundle)
+			result := crdUpgradeResult{
+				From:     from.bundle.ID(),
+				To:       to.bundle.ID(),
+				Safe:     len(findings) == 0,
+				Findings: findings,
+			}
+			if result.Findings == nil {
+				result.Findings = []registryv1.CRDUpgradeFinding{}
+			}
+			if err := output.print(cmd.OutOrStdout(), result, result.printText); err != nil {
+				return &ExitError{Code: exitCodeError, Err: err}
+			}
+			if !result.Safe {
+				return &ExitError{Code: exitCodeInvalid}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fromRef, "old-ref", "", "tag or digest of the bundle manifest to use in the old kpm file")
+	cmd.Flags().StringVar(&toRef, "new-ref", "", "tag or digest of the bundle manifest to use in the new kpm file")
+	output.bindFlags(cmd)
+	return cmd
+}
+
+func (r crdUpgradeResult) printText(w io.Writer) error {
+	if r.Safe {
+		_, err := fmt.Fprintf(w, "CRD upgrade from %s to %s is safe\n", r.From, r.To)
+		return err
+	}
+	fmt.Fprintf(w, "CRD upgrade from %s to %s has breaking changes:\n", r.From, r.To)
+	for _, f := range r.Findings {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+// maxTextValueLength is the length after which field values are truncated in
+// text output. Use a structured output format to see complete values.
+const maxTextValueLength = 120
+
+type bundleDiff struct {
+	registryv1.BundleDiff
+}
+
+func Diff() *cobra.Command {
+	var (
+		fromRef  string
+		toRef    string
+		exitCode bool
+		output   outputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Show semantic differences between two bundles",
+		Long: `Show semantic differences between two bundles.
+
+Each bundle can be a bundle directory or a kpm file. Objects are matched by
+group, kind, and name (the CSV is matched regardless of name), and changed
+objects are compared field by field. Lists of named elements, such as CRD
+versions and containers, are compared by name rather than by position.
+
+A summary of the CSV changes that matter most when reviewing an upgrade is
+shown first: version, replaces, skips, install modes, images, and
+permissions.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := output.validate(); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			from, err := loadBundle(ctx, args[0], fromRef)
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", args[0], err)
+			}
+			to, err := loadBundle(ctx, args[1], toRef)
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", args[1], err)
+			}
+			d, err := registryv1.Diff(from.bundle, to.bundle)
+			if err != nil {
+				return err
+			}
+			result := bundleDiff{d}
+			if err := output.print(cmd.OutOrStdout(), result, result.printText); err != nil {
+				return err
+			}
+			if exitCode && !d.Empty() {
+				return &ExitError{Code: 1}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fromRef, "old-ref", "", "tag or digest of the bundle manifest to use in the old kpm file")
+	cmd.Flags().StringVar(&toRef, "new-ref", "", "tag or digest of the bundle manifest to use in the new kpm file")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "exit with status 1 if there are differences")
+	output.bindFlags(cmd)
+	return cmd
+}
+
+func (d bundleDiff) printText(w io.Writer) error {
+	fmt.Fprintf(w, "Bundle: %s -> %s\n", d.From, d.To)
+	if d.Empty() {
+		fmt.Fprintln(w, "No differences")
+		return nil
+	}
+
+	csv := d.CSV
+	fmt.Fprintln(w, "CSV:")
+	var rows [][]string
+	if csv.Version.Changed() {
+		rows = append(rows, []string{"Version:", fmt.Sprintf("%s -> %s", textOrNone(csv.Version.From), textOrNone(csv.Version.To))})
+	}
+	if csv.Replaces.Changed() {
+		rows = append(rows, []string{"Replaces:", fmt.Sprintf("%s -> %s", textOrNone(csv.Replaces.From), textOrNone(csv.Replaces.To))})
+	}
+	rows = appendChangeRows(rows, "Skips:", csv.AddedSkips, csv.RemovedSkips)
+	rows = appendChangeRows(rows, "Install modes:", csv.AddedInstallModes, csv.RemovedInstallModes)
+	rows = appendChangeRows(rows, "Images:", csv.AddedImages, csv.RemovedImages)
+	rows = appendChangeRows(rows, "Permissions:", csv.AddedPermissions, csv.RemovedPermissions)
+	if len(rows) == 0 {
+		rows = append(rows, []string{"<no summarized changes>"})
+	}
+	if err := printRows(w, "  ", rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "Objects:")
+	for _, o := range d.Objects {
+		switch o.Change {
+		case registryv1.ChangeTypeAdded:
+			fmt.Fprintf(w, "  + %s\n", o)
+		case registryv1.ChangeTypeRemoved:
+			fmt.Fprintf(w, "  - %s\n", o)
+		case registryv1.ChangeTypeChanged:
+			fmt.Fprintf(w, "  ~ %s\n", o)
+			for _, f := range o.Fields {
+				switch {
+				case f.From == nil:
+					fmt.Fprintf(w, "      + %s: %s\n", f.Path, textValue(f.To))
+				case f.To == nil:
+					fmt.Fprintf(w, "      - %s: %s\n", f.Path, textValue(f.From))
+				default:
+					fmt.Fprintf(w, "      ~ %s: %s -> %s\n", f.Path, textValue(f.From), textValue(f.To))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func appendChangeRows[T any](rows [][]string, title string, added, removed []T) [][]string {
+	for _, v := range added {
+		rows = append(rows, []string{title, fmt.Sprintf("+ %v", v)})
+		title = ""
+	}
+	for _, v := range removed {
+		rows = append(rows, []string{title, fmt.Sprintf("- %v", v)})
+		title = ""
+	}
+	return rows
+}
+
+func textOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func textValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(data)
+	if len(s) > maxTextValueLength {
+		s = s[:maxTextValueLength] + "..."
+	}
+	return s
+}
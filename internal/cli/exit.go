@@ -0,0 +1,21 @@
+package cli
+
+import "fmt"
+
+// ExitError is returned by commands that exit with a specific status code.
+// If Err is nil, the command has already reported the failure.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
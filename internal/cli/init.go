@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/renameio/v2"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+const (
+	defaultSpecFileName = "bundle.kpmspec.yaml"
+	manifestsDirName    = "manifests"
+	metadataDirName     = "metadata"
+)
+
+type scaffoldedSpec struct {
+	bundleDir string
+	specFile  string
+	id        string
+}
+
+func Init() *cobra.Command {
+	var (
+		outputDir string
+		force     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "init [directory]",
+		Short: "Scaffold kpm spec files for existing bundle directories",
+		Long: `Scaffold kpm spec files for existing bundle directories.
+
+If the directory (default: the current directory) is a registry+v1 bundle
+directory, containing manifests/ and metadata/ subdirectories, a spec file
+named bundle.kpmspec.yaml is written to the current directory.
+
+Otherwise, each subdirectory of the directory that is a bundle directory is
+scaffolded, and a spec file named <subdirectory>.kpmspec.yaml is written next
+to it. This is useful for directories of versioned bundles.
+
+Every bundle is loaded and validated before any spec file is written. Spec
+file paths to bundle directories are relative to the spec file.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+
+			specs, err := planScaffold(dir, outputDir)
+			if err != nil {
+				return err
+			}
+			if !force {
+				for _, s := range specs {
+					if _, err := os.Stat(s.specFile); err == nil {
+						return fmt.Errorf("spec file %s already exists; use --force to overwrite it", s.specFile)
+					}
+				}
+			}
+			for _, s := range specs {
+				if err := s.write(); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Spec for %s written to %s\n", s.id, s.specFile)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory to write spec files to (default: the current directory for a single bundle, or the bundle parent directory for multiple bundles)")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing spec files")
+	return cmd
+}
+
+// planScaffold finds and validates the bundle directories in dir, and
+// determines the spec file to write for each.
+func planScaffold(dir, outputDir string) ([]scaffoldedSpec, error) {
+	isBundle, err := isBundleDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+	if isBundle {
+		if outputDir == "" {
+			outputDir = "."
+		}
+		s, err := newScaffoldedSpec(dir, filepath.Join(outputDir, defaultSpecFileName))
+		if err != nil {
+			return nil, err
+		}
+		return []scaffoldedSpec{s}, nil
+	}
+
+	if outputDir == "" {
+		outputDir = dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		specs []scaffoldedSpec
+		errs  []error
+	)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bundleDir := filepath.Join(dir, entry.Name())
+		isBundle, err := isBundleDirectory(bundleDir)
+		if err != nil {
+			return nil, err
+		}
+		if !isBundle {
+			continue
+		}
+		s, err := newScaffoldedSpec(bundleDir, filepath.Join(outputDir, entry.Name()+".kpmspec.yaml"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		specs = append(specs, s)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no bundle directories found in %s", dir)
+	}
+	return specs, nil
+}
+
+func isBundleDirectory(dir string) (bool, error) {
+	for _, sub := range []string{manifestsDirName, metadataDirName} {
+		info, err := os.Stat(filepath.Join(dir, sub))
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !info.IsDir() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func newScaffoldedSpec(bundleDir, specFile string) (scaffoldedSpec, error) {
+	b, err := registryv1.NewBundleFSLoader(os.DirFS(bundleDir)).Load()
+	if err != nil {
+		return scaffoldedSpec{}, fmt.Errorf("%s: %w", bundleDir, err)
+	}
+	return scaffoldedSpec{bundleDir: bundleDir, specFile: specFile, id: b.ID()}, nil
+}
+
+func (s scaffoldedSpec) write() error {
+	absSpecDir, err := filepath.Abs(filepath.Dir(s.specFile))
+	if err != nil {
+		return err
+	}
+	absBundleDir, err := filepath.Abs(s.bundleDir)
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(absSpecDir, absBundleDir)
+	if err != nil {
+		return err
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath != "." && !strings.HasPrefix(relPath, "../") {
+		relPath = "./" + relPath
+	}
+
+	spec := specsv1.RegistryV1{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: specsv1.GroupVersion.String(),
+			Kind:       specsv1.KindRegistryV1,
+		},
+		Source: specsv1.RegistryV1Source{
+			SourceType:      specsv1.RegistryV1SourceTypeBundleDirectory,
+			BundleDirectory: &specsv1.RegistryV1BundleDirectorySource{Path: relPath},
+		},
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.specFile), 0755); err != nil {
+		return err
+	}
+	return renameio.WriteFile(s.specFile, data, 0644)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/spec"
+)
+
+func Test_Init(t *testing.T) {
+	initCmd := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := Init()
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		err := cmd.ExecuteContext(t.Context())
+		return out.String(), err
+	}
+	// requireBuilds loads the spec file and builds its spec, which must have
+	// the expected ID.
+	requireBuilds := func(t *testing.T, specFile string, expectedID string) {
+		t.Helper()
+		specs, err := (&specOptions{}).loadSpecs(t.Context(), specFile)
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+		require.Equal(t, expectedID, specs[0].ID())
+		report, err := spec.Build(t.Context(), specs[0], spec.BuildOptions{Output: spec.OutputOptions{Directory: t.TempDir()}})
+		require.NoError(t, err)
+		require.FileExists(t, report.OutputFile)
+	}
+
+	t.Run("single bundle", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeTestBundle(t, "bundle")
+
+		out, err := initCmd(t, "bundle")
+		require.NoError(t, err)
+		require.Equal(t, "Spec for example.v1.2.3 written to bundle.kpmspec.yaml\n", out)
+		data, err := os.ReadFile("bundle.kpmspec.yaml")
+		require.NoError(t, err)
+		require.Contains(t, string(data), "path: ./bundle\n")
+		requireBuilds(t, "bundle.kpmspec.yaml", "example.v1.2.3")
+
+		_, err = initCmd(t, "bundle")
+		require.ErrorContains(t, err, "spec file bundle.kpmspec.yaml already exists; use --force to overwrite it")
+		_, err = initCmd(t, "--force", "bundle")
+		require.NoError(t, err)
+	})
+
+	t.Run("multiple bundles", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		for _, version := range []string{"1.2.3", "1.3.0"} {
+			dir := filepath.Join("bundles", version)
+			writeTestBundle(t, dir)
+			csv := strings.ReplaceAll(testBundleFiles["manifests/csv.yaml"], "1.2.3", version)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "manifests", "csv.yaml"), []byte(csv), 0o644))
+		}
+		require.NoError(t, os.MkdirAll(filepath.Join("bundles", "docs"), 0o755))
+
+		out, err := initCmd(t, "bundles")
+		require.NoError(t, err)
+		require.Equal(t, strings.Join([]string{
+			"Spec for example.v1.2.3 written to " + filepath.Join("bundles", "1.2.3.kpmspec.yaml"),
+			"Spec for example.v1.3.0 written to " + filepath.Join("bundles", "1.3.0.kpmspec.yaml"),
+		}, "\n")+"\n", out)
+		requireBuilds(t, filepath.Join("bundles", "1.2.3.kpmspec.yaml"), "example.v1.2.3")
+		requireBuilds(t, filepath.Join("bundles", "1.3.0.kpmspec.yaml"), "example.v1.3.0")
+		require.NoFileExists(t, filepath.Join("bundles", "docs.kpmspec.yaml"))
+	})
+
+	t.Run("invalid bundle", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeTestBundle(t, "bundle")
+		require.NoError(t, os.Remove(filepath.Join("bundle", "metadata", "annotations.yaml")))
+
+		_, err := initCmd(t, "bundle")
+		require.ErrorContains(t, err, "annotations.yaml not found")
+		require.NoFileExists(t, "bundle.kpmspec.yaml")
+	})
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/version"
+)
+
+type inspectResult struct {
+	registryv1.Summary
+	Image inspectImage `json:"image"`
+}
+
+type inspectImage struct {
+	Manifest ocispec.Descriptor   `json:"manifest"`
+	Config   ocispec.Descriptor   `json:"config"`
+	Layers   []ocispec.Descriptor `json:"layers"`
+	// Referrers are the artifacts attached to the manifest, such as its
+	// provenance and SBOMs.
+	Referrers []ocispec.Descriptor `json:"referrers,omitempty"`
+}
+
+func Inspect() *cobra.Command {
+	var (
+		ref        string
+		sbomFormat string
+		output     outputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inspect <kpm-file|bundle-directory>",
+		Short: "Summarize the contents of a kpm file or bundle directory",
+		Long: `Summarize the contents of a kpm file or bundle directory.
+
+For bundle directories, the image digests and sizes are those of the image
+that would be built from the directory.
+
+With --sbom, the SBOM attached to the bundle image (see kpm build
+--attach-sbom) is printed instead. If several SBOMs are attached, choose one
+with --sbom=spdx or --sbom=cyclonedx. For bundle directories, the SBOM is
+generated.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := output.validate(); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			lb, err := loadBundle(ctx, args[0], ref)
+			if err != nil {
+				return err
+			}
+			referrers, err := ocilayout.Referrers(ctx, lb.target, lb.manifest, "")
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("sbom") {
+				data, err := inspectSBOM(ctx, lb, referrers, args[0], sbomFormat)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(append(data, '\n'))
+				return err
+			}
+
+			manifest, err := ocilayout.FetchManifest(ctx, lb.target, lb.manifest)
+			if err != nil {
+				return err
+			}
+
+			result := inspectResult{
+				Summary: lb.bundle.Summary(),
+				Image: inspectImage{
+					Manifest:  lb.manifest,
+					Config:    manifest.Config,
+					Layers:    manifest.Layers,
+					Referrers: referrers,
+				},
+			}
+			return output.print(cmd.OutOrStdout(), result, result.printText)
+		},
+	}
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to inspect in the kpm file")
+	cmd.Flags().StringVar(&sbomFormat, "sbom", "", fmt.Sprintf("print the SBOM of the bundle instead, optionally in the given format: one of %s", strings.Join(sbom.Formats, ", ")))
+	cmd.Flags().Lookup("sbom").NoOptDefVal = sbomFormatAny
+	output.bindFlags(cmd)
+	return cmd
+}
+
+func (r inspectResult) printText(w io.Writer) error {
+	installModes := make([]string, 0, len(r.InstallModes))
+	for _, installMode := range r.InstallModes {
+		installModes = append(installModes, string(installMode))
+	}
+	if err := printRows(w, "", [][]string{
+		{"ID:", r.ID},
+		{"Package:", r.Package},
+		{"Version:", r.Version},
+		{"Channels:", strings.Join(r.Channels, ", ")},
+		{"Default channel:", r.DefaultChannel},
+		{"Install modes:", strings.Join(installModes, ", ")},
+	}); err != nil {
+		return err
+	}
+
+	var owned, required, relatedImages, manifests, image [][]string
+	for _, crd := range r.OwnedCRDs {
+		owned = append(owned, []string{crd.String()})
+	}
+	for _, crd := range r.RequiredCRDs {
+		required = append(required, []string{crd.String()})
+	}
+	for _, img := range r.RelatedImages {
+		relatedImages = append(relatedImages, []string{img.Name, img.Image})
+	}
+	for _, m := range r.Manifests {
+		manifests = append(manifests, []string{m.File, fmt.Sprintf("%s/%s", m.Kind, m.Name)})
+	}
+	image = append(image,
+		[]string{"manifest", r.Image.Manifest.Digest.String(), fmt.Sprintf("%d bytes", r.Image.Manifest.Size)},
+		[]string{"config", r.Image.Config.Digest.String(), fmt.Sprintf("%d bytes", r.Image.Config.Size)},
+	)
+	for _, layer := range r.Image.Layers {
+		image = append(image, []string{"layer", layer.Digest.String(), fmt.Sprintf("%d bytes", layer.Size)})
+	}
+	for _, referrer := range r.Image.Referrers {
+		image = append(image, []string{"referrer", referrer.Digest.String(), referrer.ArtifactType})
+	}
+
+	for _, section := range []struct {
+		title string
+		rows  [][]string
+	}{
+		{"Owned CRDs:", owned},
+		{"Required CRDs:", required},
+		{"Related images:", relatedImages},
+		{"Manifests:", manifests},
+		{"Image:", image},
+	} {
+		if len(section.rows) == 0 {
+			fmt.Fprintf(w, "%s <none>\n", section.title)
+			continue
+		}
+		fmt.Fprintln(w, section.title)
+		if err := printRows(w, "  ", section.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printRows(w io.Writer, indent string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s%s\n", indent, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// sbomFormatAny is the value of --sbom when no format is given.
+const sbomFormatAny = "any"
+
+// inspectSBOM returns the SBOM attached to the bundle image in the given
+// format. Bundle directories have no attached SBOMs, so for them the SBOM is
+// generated.
+func inspectSBOM(ctx context.Context, lb *loadedBundle, referrers []ocispec.Descriptor, path string, format string) ([]byte, error) {
+	if format != sbomFormatAny {
+		if _, err := sbom.MediaType(format); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		matches []ocispec.Descriptor
+		formats []string
+	)
+	for _, referrer := range referrers {
+		referrerFormat := sbom.FormatForMediaType(referrer.ArtifactType)
+		if referrerFormat == "" || (format != sbomFormatAny && format != referrerFormat) {
+			continue
+		}
+		matches = append(matches, referrer)
+		formats = append(formats, referrerFormat)
+	}
+
+	switch len(matches) {
+	case 0:
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("no SBOM attached to %s", path)
+		}
+		if format == sbomFormatAny {
+			format = sbom.FormatSPDX
+		}
+		inv, err := lb.bundle.Inventory()
+		if err != nil {
+			return nil, err
+		}
+		return sbom.Generate(format, inv, sbom.Options{Created: time.Now(), ToolVersion: version.Version()})
+	case 1:
+		_, data, err := ocilayout.FetchArtifact(ctx, lb.target, matches[0])
+		return data, err
+	default:
+		return nil, fmt.Errorf("multiple SBOMs attached to %s (%s): choose one with --sbom=<format>", path, strings.Join(formats, ", "))
+	}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputText       = "text"
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputGoTemplate = "go-template="
+)
+
+type outputOptions struct {
+	format string
+}
+
+func (o *outputOptions) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.format, "output", "o", outputText, fmt.Sprintf("output format: one of %s, %s, %s, or %s<template>", outputText, outputJSON, outputYAML, outputGoTemplate))
+}
+
+func (o *outputOptions) validate() error {
+	switch {
+	case o.format == outputText, o.format == outputJSON, o.format == outputYAML:
+		return nil
+	case strings.HasPrefix(o.format, outputGoTemplate):
+		_, err := o.template()
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", o.format)
+	}
+}
+
+func (o *outputOptions) template() (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(strings.TrimPrefix(o.format, outputGoTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// print writes v to w in the selected format. For the text format, printText
+// is called. Go templates are executed against the JSON representation of v,
+// so template fields use the JSON field names.
+func (o *outputOptions) print(w io.Writer, v any, printText func(io.Writer) error) error {
+	switch {
+	case o.format == outputText:
+		return printText(w)
+	case o.format == outputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case o.format == outputYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case strings.HasPrefix(o.format, outputGoTemplate):
+		tmpl, err := o.template()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var obj any
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		return tmpl.Execute(w, obj)
+	default:
+		return fmt.Errorf("unknown output format %q", o.format)
+	}
+}
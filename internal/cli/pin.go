@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+)
+
+type pinImagesOptions struct {
+	enabled bool
+	layout  string
+	mapping string
+}
+
+func (o *pinImagesOptions) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.enabled, "pin-images", false, "pin the images referenced by each bundle to digests, resolving them against their registries unless --image-layout or --image-mapping is set")
+	cmd.Flags().StringVar(&o.layout, "image-layout", "", "resolve images offline using the tags of this OCI image layout directory or archive; implies --pin-images")
+	cmd.Flags().StringVar(&o.mapping, "image-mapping", "", "resolve images offline using this YAML file mapping image references to digests; implies --pin-images")
+}
+
+// resolver returns the image resolver selected by the options, or nil if
+// images should not be pinned. Images are resolved against their registries
+// using remote.
+func (o *pinImagesOptions) resolver(ctx context.Context, remote remoteOptions) (imageresolver.Resolver, error) {
+	switch {
+	case o.layout != "" && o.mapping != "":
+		return nil, errors.New("only one of --image-layout or --image-mapping can be set")
+	case o.layout != "":
+		return imageresolver.NewLayout(ctx, o.layout)
+	case o.mapping != "":
+		return imageresolver.NewMapping(o.mapping)
+	case o.enabled:
+		client, err := remote.newClient()
+		if err != nil {
+			return nil, err
+		}
+		return imageresolver.NewRegistry(client, remote.plainHTTP), nil
+	default:
+		return nil, nil
+	}
+}
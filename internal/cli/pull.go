@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+func Pull() *cobra.Command {
+	var (
+		outputFile string
+		remote     remoteOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull <image-reference>",
+		Short: "Pull a bundle image from an image registry into a kpm file",
+		Long: `Pull a bundle image from an image registry into a kpm file.
+
+Unless --output is specified, the kpm file is named <package>.v<version>.kpm,
+using the package name from the image config labels and the version from the
+bundle's CSV.
+
+Artifacts attached to the image, such as signatures, SBOMs and provenance,
+are pulled into the kpm file as well.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			src, err := remote.newRepository(args[0])
+			if err != nil {
+				return err
+			}
+			desc, err := src.Resolve(ctx, src.Reference.ReferenceOrDefault())
+			if err != nil {
+				return fmt.Errorf("failed to resolve %q: %w", args[0], err)
+			}
+
+			// Pull the image and its referrers (e.g. signatures) into memory
+			// first so that it can be inspected to determine its name before
+			// the kpm file is written.
+			pulled := memory.New()
+			if err := oras.ExtendedCopyGraph(ctx, src, pulled, desc, oras.DefaultExtendedCopyGraphOptions); err != nil {
+				return fmt.Errorf("failed to pull %q: %w", args[0], err)
+			}
+
+			id, imageNameTag, err := registryv1.ImageID(ctx, pulled, desc)
+			if err != nil {
+				return err
+			}
+			if outputFile == "" {
+				outputFile = fmt.Sprintf("%s.kpm", id)
+			}
+
+			if err := ocilayout.WriteArchive(ctx, outputFile, func(store *oci.Store) error {
+				return ocilayout.Push(ctx, pulled, desc, store, []string{imageNameTag})
+			}); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s written to %s (digest: %s)\n", id, outputFile, desc.Digest)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "path to write the kpm file (default <package>.v<version>.kpm)")
+	remote.bindFlags(cmd)
+	return cmd
+}
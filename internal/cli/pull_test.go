@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"crypto"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/signing"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+func Test_Pull(t *testing.T) {
+	host := newTestRegistry(t)
+	kpmFile := filepath.Join(t.TempDir(), "example.kpm")
+	publicKey := writeSignedTestKPMFile(t, kpmFile)
+
+	src, err := ocilayout.OpenArchive(t.Context(), kpmFile)
+	require.NoError(t, err)
+	desc, err := ocilayout.ResolveManifest(t.Context(), src, "")
+	require.NoError(t, err)
+	repo, err := registryclient.NewRepository(host+"/example/bundle", true)
+	require.NoError(t, err)
+	require.NoError(t, ocilayout.Push(t.Context(), src, desc, repo, []string{"v1"}))
+
+	pull := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := Pull()
+		cmd.SetArgs(append([]string{"--plain-http"}, args...))
+		cmd.SetOut(&out)
+		err := cmd.ExecuteContext(t.Context())
+		return out.String(), err
+	}
+	// requirePulled checks that the kpm file at path contains the signed
+	// bundle manifest, tagged with its package and version.
+	requirePulled := func(t *testing.T, path string) {
+		t.Helper()
+		require.NoError(t, ocilayout.VerifyArchive(t.Context(), path))
+		store, err := ocilayout.OpenArchive(t.Context(), path)
+		require.NoError(t, err)
+		actual, err := store.Resolve(t.Context(), "example:1.2.3")
+		require.NoError(t, err)
+		require.Equal(t, desc.Digest, actual.Digest)
+		signatures, err := signing.Verify(t.Context(), store, actual, []crypto.PublicKey{publicKey})
+		require.NoError(t, err)
+		require.Len(t, signatures, 1)
+	}
+
+	t.Run("default output", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		out, err := pull(t, host+"/example/bundle:v1")
+		require.NoError(t, err)
+		require.Equal(t, "example.v1.2.3 written to example.v1.2.3.kpm (digest: "+desc.Digest.String()+")\n", out)
+		requirePulled(t, "example.v1.2.3.kpm")
+	})
+	t.Run("output file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "pulled.kpm")
+		_, err := pull(t, "--output", output, host+"/example/bundle@"+desc.Digest.String())
+		require.NoError(t, err)
+		requirePulled(t, output)
+	})
+	t.Run("missing tag", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "pulled.kpm")
+		_, err := pull(t, "--output", output, host+"/example/bundle:missing")
+		require.ErrorContains(t, err, `failed to resolve "`+host+`/example/bundle:missing"`)
+		require.NoFileExists(t, output)
+	})
+}
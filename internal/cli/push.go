@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+func Push() *cobra.Command {
+	var (
+		extraTags []string
+		remote    remoteOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push <kpm-file> <repository>[:<tag>]",
+		Short: "Push a kpm file to an image registry",
+		Long: `Push a kpm file to an image registry.
+
+If no tag is specified in the repository reference, the tags that were applied
+when the kpm file was built (i.e. the bundle version and any output tags) are
+used. Additional tags can be applied with --tag.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			src, err := ocilayout.OpenArchive(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			desc, err := ocilayout.ResolveManifest(ctx, src, "")
+			if err != nil {
+				return err
+			}
+
+			image, err := registryclient.ParseReference(args[1])
+			if err != nil {
+				return err
+			}
+			dst, err := remote.newRepository(args[1])
+			if err != nil {
+				return err
+			}
+			var tags []string
+			if tag := dst.Reference.Reference; tag != "" {
+				if err := dst.Reference.ValidateReferenceAsTag(); err != nil {
+					return err
+				}
+				tags = []string{tag}
+			} else {
+				tags, err = ocilayout.ManifestTags(ctx, src, desc)
+				if err != nil {
+					return err
+				}
+			}
+			for _, tag := range extraTags {
+				if !slices.Contains(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+
+			if err := ocilayout.Push(ctx, src, desc, dst, tags); err != nil {
+				return err
+			}
+			for _, tag := range tags {
+				image.Reference = tag
+				fmt.Fprintf(cmd.OutOrStdout(), "%s pushed to %s (digest: %s)\n", args[0], image, desc.Digest)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&extraTags, "tag", nil, "additional tags to apply in the repository")
+	remote.bindFlags(cmd)
+	return cmd
+}
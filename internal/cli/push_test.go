@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"log"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/operator-framework/kpm/internal/pkg/signing"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+// newTestRegistry starts an in-memory registry and returns its host. The
+// registry does not support the referrers API, so referrers are found through
+// the referrers tag schema.
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// writeSignedTestKPMFile builds the test bundle into a kpm file at path and
+// signs it, returning the public key of the signature.
+func writeSignedTestKPMFile(t *testing.T, path string) crypto.PublicKey {
+	t.Helper()
+	writeTestKPMFile(t, path)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, ocilayout.UpdateArchive(t.Context(), path, func(store *oci.Store) error {
+		subject, err := ocilayout.ResolveManifest(t.Context(), store, "")
+		if err != nil {
+			return err
+		}
+		_, err = signing.Sign(t.Context(), store, subject, "example", key)
+		return err
+	}))
+	return key.Public()
+}
+
+func Test_Push(t *testing.T) {
+	host := newTestRegistry(t)
+	kpmFile := filepath.Join(t.TempDir(), "example.kpm")
+	publicKey := writeSignedTestKPMFile(t, kpmFile)
+
+	push := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := Push()
+		cmd.SetArgs(append([]string{"--plain-http"}, args...))
+		cmd.SetOut(&out)
+		err := cmd.ExecuteContext(t.Context())
+		return out.String(), err
+	}
+	// requireTags checks that each of tags resolves to the signed bundle
+	// manifest in the repository.
+	requireTags := func(t *testing.T, repoName string, tags ...string) {
+		t.Helper()
+		repo, err := remote.NewRepository(host + "/" + repoName)
+		require.NoError(t, err)
+		repo.PlainHTTP = true
+		for _, tag := range tags {
+			desc, err := repo.Resolve(t.Context(), tag)
+			require.NoError(t, err)
+			signatures, err := signing.Verify(t.Context(), repo, desc, []crypto.PublicKey{publicKey})
+			require.NoError(t, err)
+			require.Len(t, signatures, 1)
+		}
+	}
+
+	t.Run("build tags", func(t *testing.T) {
+		out, err := push(t, "--tag", "latest", kpmFile, host+"/example/bundle")
+		require.NoError(t, err)
+		require.Contains(t, out, "pushed to "+host+"/example/bundle:1.2.3 ")
+		require.Contains(t, out, "pushed to "+host+"/example/bundle:latest ")
+		requireTags(t, "example/bundle", "1.2.3", "latest")
+	})
+	t.Run("explicit tag", func(t *testing.T) {
+		out, err := push(t, kpmFile, host+"/example/explicit:stable")
+		require.NoError(t, err)
+		require.Equal(t, 1, strings.Count(out, "pushed to"))
+		requireTags(t, "example/explicit", "stable")
+
+		repo, err := remote.NewRepository(host + "/example/explicit")
+		require.NoError(t, err)
+		repo.PlainHTTP = true
+		_, err = repo.Resolve(t.Context(), "1.2.3")
+		require.Error(t, err)
+	})
+	t.Run("digest reference", func(t *testing.T) {
+		_, err := push(t, kpmFile, host+"/example/bundle@sha256:"+strings.Repeat("0", 64))
+		require.ErrorContains(t, err, "invalid tag")
+	})
+}
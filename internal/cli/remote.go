@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+type remoteOptions struct {
+	plainHTTP bool
+}
+
+func (o *remoteOptions) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.plainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS to connect to the registry")
+}
+
+// newRepository returns a remote repository for the image reference ref,
+// which is normalized like container runtimes normalize it (e.g. nginx:1
+// refers to docker.io/library/nginx:1), that authenticates using the
+// credentials from the local docker configuration.
+func (o *remoteOptions) newRepository(ref string) (*remote.Repository, error) {
+	return registryclient.NewRepository(ref, o.plainHTTP)
+}
+
+// newClient returns a registry client that authenticates using the
+// credentials from the local docker configuration.
+func (o *remoteOptions) newClient() (*auth.Client, error) {
+	return registryclient.NewClient()
+}
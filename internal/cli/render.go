@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+func Render() *cobra.Command {
+	var (
+		ref              string
+		installNamespace string
+		watchNamespaces  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "render <kpm-file|bundle-directory>",
+		Short: "Render a bundle into the Kubernetes manifests OLM would apply",
+		Long: `Render a bundle into the Kubernetes manifests OLM would apply.
+
+The manifests are written to stdout as a multi-document YAML stream.
+
+If no watch namespaces are specified, the bundle is rendered to watch all
+namespaces if it supports the AllNamespaces install mode, and otherwise to
+watch the install namespace. The install modes supported by the bundle
+determine which watch namespaces are allowed.
+
+Webhook TLS certificates are not rendered; they must be provisioned separately.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			lb, err := loadBundle(ctx, args[0], ref)
+			if err != nil {
+				return err
+			}
+			objs, err := lb.bundle.Render(installNamespace, watchNamespaces)
+			if err != nil {
+				return err
+			}
+			return writeObjectsYAML(cmd.OutOrStdout(), objs)
+		},
+	}
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to render from the kpm file")
+	cmd.Flags().StringVarP(&installNamespace, "namespace", "n", "", "namespace in which to install the operator")
+	cmd.Flags().StringSliceVar(&watchNamespaces, "watch-namespaces", nil, "namespaces for the operator to watch (default all namespaces, if supported)")
+	_ = cmd.MarkFlagRequired("namespace")
+	return cmd
+}
+
+func writeObjectsYAML(w io.Writer, objs []client.Object) error {
+	for _, obj := range objs {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s %q: %v", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		// Drop server-populated fields that are always empty in rendered manifests.
+		unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+		if status, ok := u["status"].(map[string]any); ok && len(status) == 0 {
+			delete(u, "status")
+		}
+		data, err := yaml.Marshal(u)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,14 +2,28 @@ package cli
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/kpm/internal/pkg/version"
 )
 
 func Root(name string) *cobra.Command {
 	cmd := &cobra.Command{
-		Use: name,
+		Use:     name,
+		Version: version.Version(),
 	}
 	cmd.AddCommand(
+		Init(),
 		Build(),
+		Push(),
+		Pull(),
+		Inspect(),
+		Unpack(),
+		Render(),
+		Validate(),
+		Diff(),
+		Check(),
+		Sign(),
+		Verify(),
 	)
 	return cmd
 }
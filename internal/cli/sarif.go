@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"path/filepath"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+// The types below model the subset of the SARIF 2.1.0 format that is needed
+// to report bundle validation diagnostics.
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// newSARIFLog converts diagnostics to a SARIF log. File paths in diagnostics
+// are reported relative to baseDir.
+func newSARIFLog(diagnostics []registryv1.Diagnostic, baseDir string) sarifLog {
+	var (
+		rules   = []sarifRule{}
+		results = []sarifResult{}
+		seen    = map[string]struct{}{}
+	)
+	for _, d := range diagnostics {
+		if _, ok := seen[d.Check]; !ok {
+			seen[d.Check] = struct{}{}
+			rules = append(rules, sarifRule{ID: d.Check})
+		}
+		result := sarifResult{
+			RuleID:  d.Check,
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.File != "" {
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(filepath.Join(baseDir, d.File))},
+				},
+			}}
+		}
+		results = append(results, result)
+	}
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "kpm",
+				InformationURI: "https://github.com/operator-framework/kpm",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
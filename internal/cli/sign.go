@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/operator-framework/kpm/internal/pkg/signing"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+func Sign() *cobra.Command {
+	var (
+		keyFile  string
+		ref      string
+		identity string
+		remote   remoteOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sign <kpm-file|image-reference>",
+		Short: "Sign a kpm file or a pushed bundle image",
+		Long: `Sign a kpm file or a pushed bundle image.
+
+The bundle manifest is signed with a local ECDSA or ed25519 private key in
+PEM format (PKCS #8 or SEC 1, unencrypted). The signature is stored as an OCI
+referrer of the manifest in the cosign signature format, either inside the kpm
+file or in the image's repository, and is copied along with the image by kpm
+push and kpm pull.
+
+The signed identity is the image's repository (e.g. quay.io/org/package). For
+kpm files it defaults to the image name the kpm file was built or pulled
+with; use --identity to sign for the repository the image will be pushed to.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			signer, err := signing.LoadPrivateKey(keyFile)
+			if err != nil {
+				return err
+			}
+
+			var subject, signature ocispec.Descriptor
+			if isFile(args[0]) {
+				err = ocilayout.UpdateArchive(ctx, args[0], func(store *oci.Store) error {
+					subject, err = ocilayout.ResolveManifest(ctx, store, ref)
+					if err != nil {
+						return err
+					}
+					if identity == "" {
+						identity, err = layoutImageName(ctx, store, subject)
+						if err != nil {
+							return err
+						}
+					}
+					signature, err = signing.Sign(ctx, store, subject, identity, signer)
+					return err
+				})
+			} else {
+				repo, repoErr := remote.newRepository(args[0])
+				if repoErr != nil {
+					return repoErr
+				}
+				subject, err = repo.Resolve(ctx, repo.Reference.ReferenceOrDefault())
+				if err != nil {
+					return fmt.Errorf("failed to resolve %q: %w", args[0], err)
+				}
+				if identity == "" {
+					// Sign for the image's name, e.g. docker.io/library/nginx,
+					// rather than for the registry host that serves it.
+					image, err := registryclient.ParseReference(args[0])
+					if err != nil {
+						return err
+					}
+					identity = fmt.Sprintf("%s/%s", image.Registry, image.Repository)
+				}
+				signature, err = signing.Sign(ctx, repo, subject, identity, signer)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s signed as %s (digest: %s, signature: %s)\n", args[0], identity, subject.Digest, signature.Digest)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keyFile, "key", "", "path to the PEM-encoded private key to sign with")
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to sign in the kpm file")
+	cmd.Flags().StringVar(&identity, "identity", "", "image repository to sign the manifest for (default: the image's repository)")
+	_ = cmd.MarkFlagRequired("key")
+	remote.bindFlags(cmd)
+	return cmd
+}
+
+// isFile reports whether arg refers to a file (e.g. a kpm file) rather than
+// an image reference: an existing regular file, or an argument that ends in
+// .kpm or looks like a path, so that a missing file is reported as such
+// rather than looked up in a registry.
+func isFile(arg string) bool {
+	slashed := filepath.ToSlash(arg)
+	if strings.HasSuffix(arg, ".kpm") || filepath.IsAbs(arg) || strings.HasPrefix(slashed, "./") || strings.HasPrefix(slashed, "../") {
+		return true
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// layoutImageName returns the image name (without a tag) of the manifest
+// described by desc in target, from the tags that are full references, e.g.
+// "example" for "example:1.2.3".
+func layoutImageName(ctx context.Context, target ocilayout.ReadOnlyTarget, desc ocispec.Descriptor) (string, error) {
+	tags, err := ocilayout.Tags(ctx, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	for _, tag := range tags {
+		i := strings.LastIndex(tag, ":")
+		if i <= 0 {
+			continue
+		}
+		tagDesc, err := target.Resolve(ctx, tag)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve tag %q: %w", tag, err)
+		}
+		if tagDesc.Digest == desc.Digest {
+			return tag[:i], nil
+		}
+	}
+	return "", fmt.Errorf("no image name found for %s: --identity is required", desc.Digest)
+}
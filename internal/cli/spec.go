@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/kpm/internal/pkg/spec"
+)
+
+// specOptions configure how spec files are rendered and loaded.
+type specOptions struct {
+	valuesFiles           []string
+	setValues             []string
+	allowNondeterministic bool
+	remote                remoteOptions
+}
+
+func (o *specOptions) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&o.valuesFiles, "values", "f", nil, "YAML file with values for the spec file (can be repeated; later files take precedence)")
+	cmd.Flags().StringArrayVar(&o.setValues, "set", nil, "set a string value for the spec file, e.g. image.tag=v1.2.3 (can be repeated; takes precedence over --values)")
+	cmd.Flags().BoolVar(&o.allowNondeterministic, "allow-nondeterministic", false, "allow spec file template functions that depend on the time, randomness, or environment")
+	o.remote.bindFlags(cmd)
+}
+
+// loadSpecs loads the specs defined by the spec file at path. YAML spec files
+// define a single spec, and Starlark spec files may define several.
+func (o *specOptions) loadSpecs(ctx context.Context, path string) ([]spec.Spec, error) {
+	values, err := o.values()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, spec.StarlarkSpecFileSuffix) {
+		l := &spec.Starlark{Registry: spec.DefaultRegistry, Values: values, Load: o.loadOptions()}
+		return l.LoadSpecFile(ctx, path)
+	}
+	l := &spec.YAML{
+		Registry: spec.DefaultRegistry,
+		Template: spec.TemplateOptions{
+			Values:                values,
+			AllowNondeterministic: o.allowNondeterministic,
+		},
+		Load: o.loadOptions(),
+	}
+	s, err := l.LoadSpecFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return []spec.Spec{s}, nil
+}
+
+func (o *specOptions) loadOptions() spec.LoadOptions {
+	return spec.LoadOptions{PlainHTTP: o.remote.plainHTTP}
+}
+
+func (o *specOptions) values() (map[string]any, error) {
+	values := map[string]any{}
+	for _, valuesFile := range o.valuesFiles {
+		data, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		var fileValues map[string]any
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to parse values file %q: %v", valuesFile, err)
+		}
+		spec.MergeValues(values, fileValues)
+	}
+	for _, setValue := range o.setValues {
+		if err := spec.SetValue(values, setValue); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/util/tar"
+)
+
+func Unpack() *cobra.Command {
+	var (
+		ref   string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "unpack <kpm-file> <directory>",
+		Short: "Restore a bundle directory from a kpm file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			kpmFile, dir := args[0], args[1]
+
+			src, err := ocilayout.OpenArchive(ctx, kpmFile)
+			if err != nil {
+				return err
+			}
+			desc, err := ocilayout.ResolveManifest(ctx, src, ref)
+			if err != nil {
+				return err
+			}
+			manifest, err := ocilayout.FetchManifest(ctx, src, desc)
+			if err != nil {
+				return err
+			}
+			if len(manifest.Layers) != 1 {
+				return fmt.Errorf("expected exactly one layer in manifest %s, found %d", desc.Digest, len(manifest.Layers))
+			}
+			if manifest.Layers[0].MediaType != ocispec.MediaTypeImageLayerGzip {
+				return fmt.Errorf("expected layer media type %q, found %q", ocispec.MediaTypeImageLayerGzip, manifest.Layers[0].MediaType)
+			}
+
+			if err := prepareUnpackDirectory(dir, force); err != nil {
+				return err
+			}
+			layer, err := ocilayout.OpenLayer(ctx, src, manifest.Layers[0])
+			if err != nil {
+				return err
+			}
+			if err := tar.Extract(layer, dir); err != nil {
+				return fmt.Errorf("failed to extract layer %s: %w", manifest.Layers[0].Digest, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s unpacked to %s (digest: %s)\n", kpmFile, dir, desc.Digest)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to unpack from the kpm file")
+	cmd.Flags().BoolVar(&force, "force", false, "remove the contents of the directory if it is not empty")
+	return cmd
+}
+
+func prepareUnpackDirectory(dir string, force bool) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("directory %q is not empty: use --force to overwrite it", dir)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
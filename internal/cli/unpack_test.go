@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/spec"
+)
+
+var testBundleFiles = map[string]string{
+	"manifests/csv.yaml": `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: 1.2.3
+`,
+	"metadata/annotations.yaml": `annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: example
+`,
+}
+
+func writeTestBundle(t *testing.T, dir string) {
+	t.Helper()
+	for name, data := range testBundleFiles {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+}
+
+// writeTestKPMFile builds the test bundle into a kpm file at path.
+func writeTestKPMFile(t *testing.T, path string) {
+	t.Helper()
+	bundleDir := t.TempDir()
+	writeTestBundle(t, bundleDir)
+	b, err := registryv1.NewBundleFSLoader(os.DirFS(bundleDir)).Load()
+	require.NoError(t, err)
+	_, err = spec.Build(t.Context(), b, spec.BuildOptions{Output: spec.OutputOptions{File: path}})
+	require.NoError(t, err)
+}
+
+func Test_Unpack(t *testing.T) {
+	kpmFile := filepath.Join(t.TempDir(), "example.kpm")
+	writeTestKPMFile(t, kpmFile)
+
+	requireBundle := func(t *testing.T, dir string) {
+		t.Helper()
+		for name, data := range testBundleFiles {
+			actual, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			require.Equal(t, data, string(actual))
+		}
+	}
+	unpack := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := Unpack()
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		err := cmd.ExecuteContext(t.Context())
+		return out.String(), err
+	}
+
+	t.Run("new directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "bundle")
+		out, err := unpack(t, kpmFile, dir)
+		require.NoError(t, err)
+		require.Contains(t, out, "unpacked to "+dir)
+		requireBundle(t, dir)
+	})
+	t.Run("empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := unpack(t, kpmFile, dir)
+		require.NoError(t, err)
+		requireBundle(t, dir)
+	})
+	t.Run("non-empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := filepath.Join(dir, "stale.yaml")
+		require.NoError(t, os.WriteFile(stale, []byte("stale"), 0o644))
+
+		_, err := unpack(t, kpmFile, dir)
+		require.ErrorContains(t, err, "is not empty: use --force to overwrite it")
+		require.FileExists(t, stale)
+		require.NoDirExists(t, filepath.Join(dir, "manifests"))
+
+		_, err = unpack(t, "--force", kpmFile, dir)
+		require.NoError(t, err)
+		require.NoFileExists(t, stale)
+		requireBundle(t, dir)
+	})
+	t.Run("missing kpm file", func(t *testing.T) {
+		_, err := unpack(t, filepath.Join(t.TempDir(), "missing.kpm"), t.TempDir())
+		require.ErrorContains(t, err, "no such file or directory")
+	})
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/spec"
+)
+
+const (
+	exitCodeInvalid = 1
+	exitCodeError   = 2
+
+	validateOutputSARIF = "sarif"
+)
+
+type validateResult struct {
+	Valid       bool                    `json:"valid"`
+	Diagnostics []registryv1.Diagnostic `json:"diagnostics"`
+}
+
+func Validate() *cobra.Command {
+	var (
+		ref         string
+		output      string
+		specOptions specOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate <spec-file|bundle-directory|kpm-file>",
+		Short: "Validate a bundle without building it",
+		Long: `Validate a bundle without building it.
+
+Every validation check is run, and all failures are reported. Arguments ending
+in .kpm are treated as kpm files, directories are treated as bundle
+directories, and all other files are treated as spec files.
+
+The exit status is 0 if the bundle is valid, 1 if the bundle is invalid, and 2
+if the bundle could not be validated (e.g. due to an I/O error). Warnings do
+not make a bundle invalid.
+
+Besides the checks that every bundle must pass to be built, validate warns
+if the CSV's spec.relatedImages does not list every image referenced by its
+install strategy deployments (container and init container images and
+RELATED_IMAGE_* env vars), since image mirroring tools depend on it. Set
+populateRelatedImages: true in a RegistryV1 spec to complete the list at build
+time.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case outputText, outputJSON, validateOutputSARIF:
+			default:
+				return fmt.Errorf("unknown output format %q", output)
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			path := args[0]
+			loadErr := loadForValidation(cmd, path, ref, &specOptions)
+
+			var validationErr *registryv1.ValidationError
+			if loadErr != nil && !errors.As(loadErr, &validationErr) {
+				return &ExitError{Code: exitCodeError, Err: loadErr}
+			}
+
+			result := validateResult{Valid: true, Diagnostics: []registryv1.Diagnostic{}}
+			if validationErr != nil {
+				result = validateResult{Valid: !validationErr.HasErrors(), Diagnostics: validationErr.Diagnostics}
+			}
+			if err := printValidateResult(cmd.OutOrStdout(), output, path, result); err != nil {
+				return &ExitError{Code: exitCodeError, Err: err}
+			}
+			if !result.Valid {
+				return &ExitError{Code: exitCodeInvalid}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to validate in the kpm file")
+	specOptions.bindFlags(cmd)
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, fmt.Sprintf("output format: one of %s, %s, or %s", outputText, outputJSON, validateOutputSARIF))
+	return cmd
+}
+
+func loadForValidation(cmd *cobra.Command, path string, ref string, specOptions *specOptions) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || strings.HasSuffix(path, ".kpm") {
+		lb, err := loadBundle(cmd.Context(), path, ref)
+		if err != nil {
+			return err
+		}
+		return lb.bundle.ValidateRelatedImages()
+	}
+	specs, err := specOptions.loadSpecs(cmd.Context(), path)
+	if err != nil {
+		return err
+	}
+	return validateRelatedImages(specs)
+}
+
+// validateRelatedImages checks that the related images of every bundle spec
+// are complete. Unlike the other checks, it is not run when bundles are
+// loaded, because incomplete related images do not prevent a bundle from
+// being built.
+func validateRelatedImages(specs []spec.Spec) error {
+	var diagnostics []registryv1.Diagnostic
+	for _, s := range specs {
+		v, ok := s.(interface{ ValidateRelatedImages() error })
+		if !ok {
+			continue
+		}
+		err := v.ValidateRelatedImages()
+		var validationErr *registryv1.ValidationError
+		if errors.As(err, &validationErr) {
+			diagnostics = append(diagnostics, validationErr.Diagnostics...)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	return &registryv1.ValidationError{Diagnostics: diagnostics}
+}
+
+func printValidateResult(w io.Writer, output string, path string, result validateResult) error {
+	switch output {
+	case outputJSON:
+		return writeJSON(w, result)
+	case validateOutputSARIF:
+		baseDir := ""
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			baseDir = filepath.Clean(path)
+		}
+		return writeJSON(w, newSARIFLog(result.Diagnostics, baseDir))
+	default:
+		switch {
+		case result.Valid && len(result.Diagnostics) == 0:
+			_, err := fmt.Fprintf(w, "%s is valid\n", path)
+			return err
+		case result.Valid:
+			fmt.Fprintf(w, "%s is valid, with warnings:\n", path)
+		default:
+			fmt.Fprintf(w, "%s is invalid:\n", path)
+		}
+		for _, d := range result.Diagnostics {
+			fmt.Fprintf(w, "  %s\n", d)
+		}
+		return nil
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/kpm/internal/pkg/signing"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type verifyResult struct {
+	Verified   bool                `json:"verified"`
+	Manifest   ocispec.Descriptor  `json:"manifest"`
+	Signatures []signing.Signature `json:"signatures"`
+}
+
+func Verify() *cobra.Command {
+	var (
+		keyFile     string
+		keyringFile string
+		ref         string
+		output      outputOptions
+		remote      remoteOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify <kpm-file|image-reference>",
+		Short: "Verify the signatures of a kpm file or a pushed bundle image",
+		Long: `Verify the signatures of a kpm file or a pushed bundle image.
+
+Signatures created by kpm sign (or cosign) are verified with a PEM-encoded
+ECDSA or ed25519 public key (--key) or with a keyring file containing several
+public keys (--keyring). The bundle is verified if at least one of its
+signatures can be verified with one of the keys.
+
+kpm files are verified offline. Before checking signatures, every blob in the
+kpm file is checked against the size and digest of its descriptor.
+
+The exit status is 0 if the bundle is verified, 1 if it is not (including when
+the kpm file has been tampered with), and 2 if it could not be verified (e.g.
+due to an I/O error).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := output.validate(); err != nil {
+				return err
+			}
+			if (keyFile == "") == (keyringFile == "") {
+				return errors.New("exactly one of --key or --keyring is required")
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			keyPath := keyFile
+			if keyPath == "" {
+				keyPath = keyringFile
+			}
+			keys, err := signing.LoadPublicKeys(keyPath)
+			if err != nil {
+				return &ExitError{Code: exitCodeError, Err: err}
+			}
+
+			var (
+				result     verifyResult
+				signatures []signing.Signature
+				verifyErr  error
+			)
+			if isFile(args[0]) {
+				store, err := ocilayout.OpenArchive(ctx, args[0])
+				if err != nil {
+					return &ExitError{Code: exitCodeError, Err: err}
+				}
+				if err := ocilayout.VerifyArchive(ctx, args[0]); err != nil {
+					return &ExitError{Code: exitCodeInvalid, Err: fmt.Errorf("%s: %w", args[0], err)}
+				}
+				result.Manifest, err = ocilayout.ResolveManifest(ctx, store, ref)
+				if err != nil {
+					return &ExitError{Code: exitCodeError, Err: err}
+				}
+				signatures, verifyErr = signing.Verify(ctx, store, result.Manifest, keys)
+			} else {
+				repo, err := remote.newRepository(args[0])
+				if err != nil {
+					return &ExitError{Code: exitCodeError, Err: err}
+				}
+				result.Manifest, err = repo.Resolve(ctx, repo.Reference.ReferenceOrDefault())
+				if err != nil {
+					return &ExitError{Code: exitCodeError, Err: fmt.Errorf("failed to resolve %q: %w", args[0], err)}
+				}
+				signatures, verifyErr = signing.Verify(ctx, repo, result.Manifest, keys)
+			}
+			if verifyErr != nil && !errors.Is(verifyErr, signing.ErrUnverified) {
+				return &ExitError{Code: exitCodeError, Err: verifyErr}
+			}
+
+			result.Verified = verifyErr == nil
+			result.Signatures = signatures
+			if result.Signatures == nil {
+				result.Signatures = []signing.Signature{}
+			}
+			if err := output.print(cmd.OutOrStdout(), result, func(w io.Writer) error {
+				return result.printText(w, args[0])
+			}); err != nil {
+				return &ExitError{Code: exitCodeError, Err: err}
+			}
+			if !result.Verified {
+				return &ExitError{Code: exitCodeInvalid}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keyFile, "key", "", "path to the PEM-encoded public key to verify with")
+	cmd.Flags().StringVar(&keyringFile, "keyring", "", "path to a file of PEM-encoded public keys to verify with")
+	cmd.Flags().StringVar(&ref, "ref", "", "tag or digest of the bundle manifest to verify in the kpm file")
+	output.bindFlags(cmd)
+	remote.bindFlags(cmd)
+	return cmd
+}
+
+func (r verifyResult) printText(w io.Writer, path string) error {
+	if !r.Verified {
+		_, err := fmt.Fprintf(w, "%s is not verified: no valid signatures of %s found\n", path, r.Manifest.Digest)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s is verified (digest: %s)\n", path, r.Manifest.Digest); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(r.Signatures))
+	for _, s := range r.Signatures {
+		rows = append(rows, []string{"signature", s.Descriptor.Digest.String(), s.Identity, s.KeyID})
+	}
+	return printRows(w, "  ", rows)
+}
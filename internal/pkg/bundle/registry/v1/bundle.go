@@ -29,20 +29,38 @@ const (
 )
 
 type Bundle struct {
-	manifests *manifests
-	metadata  *metadata
+	manifests *Manifests
+	metadata  *Metadata
+	// imageAnnotations are added to the image manifest of the bundle.
+	imageAnnotations map[string]string
 }
 
 type BundleLoader interface {
 	Load() (*Bundle, error)
 }
 
+// LoadOptions transform a bundle while it is loaded. Only the transformed
+// bundle is validated, so they can also make an invalid bundle valid.
+type LoadOptions struct {
+	// MetadataOverrides, if set, are applied to the metadata of the bundle.
+	MetadataOverrides *MetadataOverrides
+	// Patches are applied, in order, to the manifests of the bundle.
+	Patches []Patch
+}
+
 type bundleFSLoader struct {
 	fsys fs.FS
+	opts LoadOptions
 }
 
 func NewBundleFSLoader(fsys fs.FS) BundleLoader {
-	return &bundleFSLoader{fsys: fsys}
+	return NewBundleFSLoaderWithOptions(fsys, LoadOptions{})
+}
+
+// NewBundleFSLoaderWithOptions returns a loader like NewBundleFSLoader that
+// transforms the bundle as described by opts.
+func NewBundleFSLoaderWithOptions(fsys fs.FS, opts LoadOptions) BundleLoader {
+	return &bundleFSLoader{fsys: fsys, opts: opts}
 }
 
 func (b *bundleFSLoader) Load() (*Bundle, error) {
@@ -52,28 +70,50 @@ func (b *bundleFSLoader) Load() (*Bundle, error) {
 		return nil, err
 	}
 
-	manifestsLoader := &manifestsFSLoader{manifestsFS}
-	metadataLoader := &metadataFSLoader{metadataFS}
+	manifestsLoader := &manifestsFSLoader{fsys: manifestsFS, patches: b.opts.Patches}
+	metadataLoader := &metadataFSLoader{fsys: metadataFS, overrides: b.opts.MetadataOverrides}
 
 	bundleManifests, manifestsErr := manifestsLoader.Load()
 	bundleMetadata, metadataErr := metadataLoader.Load()
-	if err := errors.Join(manifestsErr, metadataErr); err != nil {
+	if err := joinValidationErrors(manifestsErr, metadataErr); err != nil {
 		return nil, err
 	}
 
 	return &Bundle{manifests: bundleManifests, metadata: bundleMetadata}, nil
 }
 
+func (b *Bundle) Manifests() *Manifests {
+	return b.manifests
+}
+
+func (b *Bundle) Metadata() *Metadata {
+	return b.metadata
+}
+
+// SetImageAnnotations sets annotations to add to the image manifest that
+// the bundle is marshaled into, e.g. to record the source it was built from.
+func (b *Bundle) SetImageAnnotations(annotations map[string]string) {
+	b.imageAnnotations = annotations
+}
+
 func (b *Bundle) tag() string {
 	return b.manifests.CSV().Value().Spec.Version.String()
 }
 
 func (b *Bundle) ID() string {
-	return fmt.Sprintf("%s.v%s", b.metadata.PackageName(), b.tag())
+	return bundleID(b.metadata.PackageName(), b.tag())
 }
 
 func (b *Bundle) imageNameTag() string {
-	return fmt.Sprintf("%s:%s", b.metadata.PackageName(), b.tag())
+	return bundleImageNameTag(b.metadata.PackageName(), b.tag())
+}
+
+func bundleID(packageName, version string) string {
+	return fmt.Sprintf("%s.v%s", packageName, version)
+}
+
+func bundleImageNameTag(packageName, version string) string {
+	return fmt.Sprintf("%s:%s", packageName, version)
 }
 
 func (b *Bundle) MarshalOCI(ctx context.Context, target oras.Target) (ocispec.Descriptor, error) {
@@ -83,10 +123,11 @@ func (b *Bundle) MarshalOCI(ctx context.Context, target oras.Target) (ocispec.De
 	}
 
 	manifest := ocispec.Manifest{
-		Versioned: specs.Versioned{SchemaVersion: 2},
-		MediaType: ocispec.MediaTypeImageManifest,
-		Config:    config,
-		Layers:    layers,
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      config,
+		Layers:      layers,
+		Annotations: b.imageAnnotations,
 	}
 	manifestData, err := json.Marshal(manifest)
 	if err != nil {
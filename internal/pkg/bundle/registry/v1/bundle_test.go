@@ -66,7 +66,7 @@ annotations:
 				"metadata/dependencies.yaml": &fstest.MapFile{Data: []byte(`dependencies: []`)},
 			},
 			expected: &Bundle{
-				manifests: &manifests{
+				manifests: &Manifests{
 					csv: newCSVFromData(t, "csv.yaml", []byte(`
 apiVersion: operators.coreos.com/v1alpha1
 kind: ClusterServiceVersion
@@ -100,7 +100,7 @@ kind: Secret
 `)),
 					},
 				},
-				metadata: &metadata{
+				metadata: &Metadata{
 					annotationsFile: newFromData[Annotations](t, annotationsFileName, []byte(`
 annotations:
   operators.operatorframework.io.bundle.mediatype.v1: registry+v1
@@ -136,7 +136,7 @@ func Test_Bundle_MarshalOCI(t *testing.T) {
 		{
 			name: "succeeds",
 			bundle: Bundle{
-				manifests: &manifests{
+				manifests: &Manifests{
 					csv: newCSVFromData(t, "csv.yaml", []byte(`
 apiVersion: operators.coreos.com/v1alpha1
 kind: ClusterServiceVersion
@@ -158,7 +158,7 @@ kind: Secret
 `)),
 					},
 				},
-				metadata: &metadata{
+				metadata: &Metadata{
 					annotationsFile: newFromData[Annotations](t, annotationsFileName, []byte(`
 annotations:
   operators.operatorframework.io.bundle.mediatype.v1: registry+v1
@@ -0,0 +1,315 @@
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	CheckCRDRemoved               = "crd/removed"
+	CheckCRDScopeChanged          = "crd/scope-changed"
+	CheckCRDServedVersionRemoved  = "crd/served-version-removed"
+	CheckCRDStoredVersionRemoved  = "crd/stored-version-removed"
+	CheckCRDStorageVersionChanged = "crd/storage-version-changed"
+	CheckCRDFieldRemoved          = "crd/field-removed"
+	CheckCRDRequiredFieldAdded    = "crd/required-field-added"
+	CheckCRDEnumNarrowed          = "crd/enum-narrowed"
+	CheckCRDTypeChanged           = "crd/type-changed"
+	CheckCRDValidationTightened   = "crd/validation-tightened"
+)
+
+// CRDUpgradeFinding describes a change to a CRD that may break existing
+// clusters when upgrading from one bundle to another.
+type CRDUpgradeFinding struct {
+	Check string `json:"check"`
+	CRD   string `json:"crd"`
+	// Version is the CRD version the finding applies to, if any.
+	Version string `json:"version,omitempty"`
+	// Path is the path of the schema field the finding applies to, if any.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f CRDUpgradeFinding) String() string {
+	location := f.CRD
+	if f.Version != "" {
+		location = fmt.Sprintf("%s/%s", location, f.Version)
+	}
+	if f.Path != "" {
+		location = fmt.Sprintf("%s %s", location, f.Path)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Check, location, f.Message)
+}
+
+// CheckCRDUpgrade compares the CRDs of the from and to bundles and returns a
+// finding for each change that is unsafe for clusters that have the from
+// bundle's CRDs and custom resources installed. CRDs are matched by name.
+func CheckCRDUpgrade(from, to *Bundle) []CRDUpgradeFinding {
+	toCRDs := map[string]*apiextensionsv1.CustomResourceDefinition{}
+	for _, f := range to.manifests.CRDs() {
+		toCRDs[f.Value().Name] = f.Value()
+	}
+
+	var findings []CRDUpgradeFinding
+	for _, f := range from.manifests.CRDs() {
+		fromCRD := f.Value()
+		toCRD, ok := toCRDs[fromCRD.Name]
+		if !ok {
+			findings = append(findings, CRDUpgradeFinding{
+				Check:   CheckCRDRemoved,
+				CRD:     fromCRD.Name,
+				Message: "CRD was removed; existing custom resources will no longer be managed",
+			})
+			continue
+		}
+		findings = append(findings, CheckCRDUpgradeSafety(fromCRD, toCRD)...)
+	}
+	slices.SortStableFunc(findings, func(a, b CRDUpgradeFinding) int {
+		return cmp.Compare(a.CRD, b.CRD)
+	})
+	return findings
+}
+
+// CheckCRDUpgradeSafety compares two revisions of the same CRD and returns a
+// finding for each change that is unsafe for clusters that have the from
+// revision and its custom resources installed.
+func CheckCRDUpgradeSafety(from, to *apiextensionsv1.CustomResourceDefinition) []CRDUpgradeFinding {
+	c := crdUpgradeChecker{crd: from.Name}
+
+	if from.Spec.Scope != to.Spec.Scope {
+		c.addf(CheckCRDScopeChanged, "", "", "scope changed from %s to %s", from.Spec.Scope, to.Spec.Scope)
+	}
+
+	toVersions := map[string]apiextensionsv1.CustomResourceDefinitionVersion{}
+	for _, v := range to.Spec.Versions {
+		toVersions[v.Name] = v
+	}
+
+	for _, fromVersion := range from.Spec.Versions {
+		toVersion, ok := toVersions[fromVersion.Name]
+		if fromVersion.Storage && !ok {
+			c.addf(CheckCRDStoredVersionRemoved, fromVersion.Name, "", "storage version was removed; objects stored in this version can no longer be read")
+		}
+		if fromVersion.Served && (!ok || !toVersion.Served) {
+			c.addf(CheckCRDServedVersionRemoved, fromVersion.Name, "", "served version is no longer served; clients using this version will break")
+		}
+		if ok && (fromVersion.Served || fromVersion.Storage) {
+			c.checkSchema(fromVersion.Name, "", versionSchema(fromVersion), versionSchema(toVersion))
+		}
+	}
+
+	c.checkStorageVersion(from, to)
+	return c.findings
+}
+
+func versionSchema(v apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.JSONSchemaProps {
+	if v.Schema == nil {
+		return nil
+	}
+	return v.Schema.OpenAPIV3Schema
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceDefinitionVersion {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+type crdUpgradeChecker struct {
+	crd      string
+	findings []CRDUpgradeFinding
+}
+
+func (c *crdUpgradeChecker) addf(check, version, path, format string, args ...any) {
+	c.findings = append(c.findings, CRDUpgradeFinding{
+		Check:   check,
+		CRD:     c.crd,
+		Version: version,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkStorageVersion flags storage version changes that have no migration
+// path. Objects stored in the old storage version can only be read back if
+// that version is still defined, and they can only be converted to the new
+// storage version if a conversion webhook is configured or the two versions
+// have identical schemas.
+func (c *crdUpgradeChecker) checkStorageVersion(from, to *apiextensionsv1.CustomResourceDefinition) {
+	fromStorage, toStorage := storageVersion(from), storageVersion(to)
+	if fromStorage == nil || toStorage == nil || fromStorage.Name == toStorage.Name {
+		return
+	}
+	var oldVersionInNew *apiextensionsv1.CustomResourceDefinitionVersion
+	for i := range to.Spec.Versions {
+		if to.Spec.Versions[i].Name == fromStorage.Name {
+			oldVersionInNew = &to.Spec.Versions[i]
+		}
+	}
+	hasWebhook := to.Spec.Conversion != nil && to.Spec.Conversion.Strategy == apiextensionsv1.WebhookConverter
+	switch {
+	case oldVersionInNew == nil:
+		c.addf(CheckCRDStorageVersionChanged, toStorage.Name, "", "storage version changed from %s, which was removed; existing objects cannot be migrated", fromStorage.Name)
+	case !hasWebhook && !reflect.DeepEqual(versionSchema(*oldVersionInNew), versionSchema(*toStorage)):
+		c.addf(CheckCRDStorageVersionChanged, toStorage.Name, "", "storage version changed from %s without a conversion webhook, but the schemas of the two versions differ", fromStorage.Name)
+	}
+}
+
+// checkSchema compares the from and to schemas of a single CRD version.
+func (c *crdUpgradeChecker) checkSchema(version, path string, from, to *apiextensionsv1.JSONSchemaProps) {
+	if from == nil || to == nil {
+		return
+	}
+	displayPath := path
+	if displayPath == "" {
+		displayPath = "."
+	}
+
+	if from.Type != "" && to.Type != "" && from.Type != to.Type {
+		c.addf(CheckCRDTypeChanged, version, displayPath, "type changed from %s to %s", from.Type, to.Type)
+		return
+	}
+
+	c.checkEnum(version, displayPath, from, to)
+	c.checkValidation(version, displayPath, from, to)
+
+	fromRequired := sets.New(from.Required...)
+	for _, name := range to.Required {
+		if !fromRequired.Has(name) {
+			c.addf(CheckCRDRequiredFieldAdded, version, fieldPath(path, name), "field is now required")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(from.Properties)) {
+		fromProp := from.Properties[name]
+		toProp, ok := to.Properties[name]
+		if !ok {
+			if !preservesUnknownFields(to) {
+				c.addf(CheckCRDFieldRemoved, version, fieldPath(path, name), "field was removed; existing values will be pruned")
+			}
+			continue
+		}
+		c.checkSchema(version, fieldPath(path, name), &fromProp, &toProp)
+	}
+
+	if from.Items != nil && to.Items != nil {
+		c.checkSchema(version, path+"[*]", from.Items.Schema, to.Items.Schema)
+	}
+	if from.AdditionalProperties != nil && to.AdditionalProperties != nil {
+		c.checkSchema(version, path+"[*]", from.AdditionalProperties.Schema, to.AdditionalProperties.Schema)
+	}
+}
+
+func (c *crdUpgradeChecker) checkEnum(version, path string, from, to *apiextensionsv1.JSONSchemaProps) {
+	if len(to.Enum) == 0 {
+		return
+	}
+	if len(from.Enum) == 0 {
+		c.addf(CheckCRDEnumNarrowed, version, path, "enum added to a field that previously accepted any value")
+		return
+	}
+	toValues := sets.New[string]()
+	for _, v := range to.Enum {
+		toValues.Insert(string(v.Raw))
+	}
+	var removed []string
+	for _, v := range from.Enum {
+		if !toValues.Has(string(v.Raw)) {
+			removed = append(removed, string(v.Raw))
+		}
+	}
+	if len(removed) > 0 {
+		c.addf(CheckCRDEnumNarrowed, version, path, "enum values removed: %v", removed)
+	}
+}
+
+func (c *crdUpgradeChecker) checkValidation(version, path string, from, to *apiextensionsv1.JSONSchemaProps) {
+	tightened := func(format string, args ...any) {
+		c.addf(CheckCRDValidationTightened, version, path, format, args...)
+	}
+
+	if lowered(from.Maximum, to.Maximum) || (!from.ExclusiveMaximum && to.ExclusiveMaximum) {
+		tightened("maximum tightened from %s to %s", formatBound(from.Maximum, from.ExclusiveMaximum), formatBound(to.Maximum, to.ExclusiveMaximum))
+	}
+	if raised(from.Minimum, to.Minimum) || (!from.ExclusiveMinimum && to.ExclusiveMinimum) {
+		tightened("minimum tightened from %s to %s", formatBound(from.Minimum, from.ExclusiveMinimum), formatBound(to.Minimum, to.ExclusiveMinimum))
+	}
+	for _, l := range []struct {
+		name     string
+		from, to *int64
+		max      bool
+	}{
+		{"maxLength", from.MaxLength, to.MaxLength, true},
+		{"minLength", from.MinLength, to.MinLength, false},
+		{"maxItems", from.MaxItems, to.MaxItems, true},
+		{"minItems", from.MinItems, to.MinItems, false},
+		{"maxProperties", from.MaxProperties, to.MaxProperties, true},
+		{"minProperties", from.MinProperties, to.MinProperties, false},
+	} {
+		if (l.max && lowered(l.from, l.to)) || (!l.max && raised(l.from, l.to)) {
+			tightened("%s tightened from %s to %s", l.name, formatLimit(l.from), formatLimit(l.to))
+		}
+	}
+	if to.Pattern != "" && to.Pattern != from.Pattern {
+		tightened("pattern changed from %q to %q", from.Pattern, to.Pattern)
+	}
+	if to.Format != "" && to.Format != from.Format {
+		tightened("format changed from %q to %q", from.Format, to.Format)
+	}
+	if from.Nullable && !to.Nullable {
+		tightened("field is no longer nullable")
+	}
+	if from.UniqueItems != to.UniqueItems && to.UniqueItems {
+		tightened("items must now be unique")
+	}
+
+	fromRules := sets.New[string]()
+	for _, rule := range from.XValidations {
+		fromRules.Insert(rule.Rule)
+	}
+	for _, rule := range to.XValidations {
+		if !fromRules.Has(rule.Rule) {
+			tightened("validation rule added: %s", rule.Rule)
+		}
+	}
+}
+
+// lowered returns true if an upper bound was added or lowered.
+func lowered[T cmp.Ordered](from, to *T) bool {
+	return to != nil && (from == nil || *to < *from)
+}
+
+// raised returns true if a lower bound was added or raised.
+func raised[T cmp.Ordered](from, to *T) bool {
+	return to != nil && (from == nil || *to > *from)
+}
+
+func formatBound(v *float64, exclusive bool) string {
+	if v == nil {
+		return "<none>"
+	}
+	if exclusive {
+		return fmt.Sprintf("%v (exclusive)", *v)
+	}
+	return fmt.Sprintf("%v", *v)
+}
+
+func formatLimit(v *int64) string {
+	if v == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+func preservesUnknownFields(s *apiextensionsv1.JSONSchemaProps) bool {
+	return s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields
+}
@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+const baseUpgradeCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resources.group.example.com
+spec:
+  group: group.example.com
+  scope: Namespaced
+  names:
+    kind: Resource
+  versions:
+    - name: v1alpha1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                mode:
+                  type: string
+                  enum: [fast, slow]
+                replicas:
+                  type: integer
+                  maximum: 10
+                name:
+                  type: string
+`
+
+func Test_CheckCRDUpgradeSafety(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(*apiextensionsv1.CustomResourceDefinition)
+		expected []CRDUpgradeFinding
+	}{
+		{
+			name:   "unchanged",
+			mutate: func(*apiextensionsv1.CustomResourceDefinition) {},
+		},
+		{
+			name: "loosened validation and added optional field",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				mutateSpecSchema(crd, func(spec *apiextensionsv1.JSONSchemaProps) {
+					replicas := spec.Properties["replicas"]
+					replicas.Maximum = ptr.To(20.0)
+					spec.Properties["replicas"] = replicas
+					spec.Properties["extra"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+				})
+			},
+		},
+		{
+			name: "scope changed",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				crd.Spec.Scope = apiextensionsv1.ClusterScoped
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDScopeChanged},
+			},
+		},
+		{
+			name: "served version removed",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				crd.Spec.Versions[0].Served = false
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDServedVersionRemoved, Version: "v1alpha1"},
+			},
+		},
+		{
+			name: "storage version changed without migration path",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				v1 := *crd.Spec.Versions[0].DeepCopy()
+				v1.Name = "v1"
+				v1.Schema.OpenAPIV3Schema.Properties["spec"].Properties["mode"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+				crd.Spec.Versions[0].Storage = false
+				crd.Spec.Versions = append(crd.Spec.Versions, v1)
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDStorageVersionChanged, Version: "v1"},
+			},
+		},
+		{
+			name: "storage version removed",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				crd.Spec.Versions[0].Name = "v1"
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDStoredVersionRemoved, Version: "v1alpha1"},
+				{Check: CheckCRDServedVersionRemoved, Version: "v1alpha1"},
+				{Check: CheckCRDStorageVersionChanged, Version: "v1"},
+			},
+		},
+		{
+			name: "schema tightened",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				mutateSpecSchema(crd, func(spec *apiextensionsv1.JSONSchemaProps) {
+					delete(spec.Properties, "name")
+					mode := spec.Properties["mode"]
+					mode.Enum = mode.Enum[:1]
+					spec.Properties["mode"] = mode
+					replicas := spec.Properties["replicas"]
+					replicas.Maximum = ptr.To(5.0)
+					spec.Properties["replicas"] = replicas
+					spec.Required = []string{"replicas"}
+				})
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDRequiredFieldAdded, Version: "v1alpha1", Path: "spec.replicas"},
+				{Check: CheckCRDEnumNarrowed, Version: "v1alpha1", Path: "spec.mode"},
+				{Check: CheckCRDFieldRemoved, Version: "v1alpha1", Path: "spec.name"},
+				{Check: CheckCRDValidationTightened, Version: "v1alpha1", Path: "spec.replicas"},
+			},
+		},
+		{
+			name: "type changed",
+			mutate: func(crd *apiextensionsv1.CustomResourceDefinition) {
+				mutateSpecSchema(crd, func(spec *apiextensionsv1.JSONSchemaProps) {
+					spec.Properties["replicas"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+				})
+			},
+			expected: []CRDUpgradeFinding{
+				{Check: CheckCRDTypeChanged, Version: "v1alpha1", Path: "spec.replicas"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from apiextensionsv1.CustomResourceDefinition
+			require.NoError(t, yaml.Unmarshal([]byte(baseUpgradeCRD), &from))
+			to := from.DeepCopy()
+			tt.mutate(to)
+
+			var actual []CRDUpgradeFinding
+			for _, f := range CheckCRDUpgradeSafety(&from, to) {
+				require.Equal(t, "resources.group.example.com", f.CRD)
+				require.NotEmpty(t, f.Message)
+				actual = append(actual, CRDUpgradeFinding{Check: f.Check, Version: f.Version, Path: f.Path})
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func Test_CheckCRDUpgrade_RemovedCRD(t *testing.T) {
+	from, err := NewBundleFSLoader(validBundleFS()).Load()
+	require.NoError(t, err)
+
+	toFS := validBundleFS()
+	delete(toFS, "manifests/crd.yaml")
+	toFS["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.3.0
+spec:
+  version: "1.3.0"
+`)}
+	to, err := NewBundleFSLoader(toFS).Load()
+	require.NoError(t, err)
+
+	require.Equal(t, []CRDUpgradeFinding{{
+		Check:   CheckCRDRemoved,
+		CRD:     "resources.group.example.com",
+		Message: "CRD was removed; existing custom resources will no longer be managed",
+	}}, CheckCRDUpgrade(from, to))
+	require.Empty(t, CheckCRDUpgrade(from, from))
+}
+
+func mutateSpecSchema(crd *apiextensionsv1.CustomResourceDefinition, mutate func(*apiextensionsv1.JSONSchemaProps)) {
+	spec := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+	mutate(&spec)
+	crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+}
@@ -0,0 +1,427 @@
+package v1
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+type ChangeType string
+
+const (
+	ChangeTypeAdded   ChangeType = "added"
+	ChangeTypeRemoved ChangeType = "removed"
+	ChangeTypeChanged ChangeType = "changed"
+)
+
+// BundleDiff describes the differences between two bundles.
+type BundleDiff struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	CSV     CSVDiff      `json:"csv"`
+	Objects []ObjectDiff `json:"objects"`
+}
+
+// Empty returns true if the bundles are semantically identical.
+func (d BundleDiff) Empty() bool {
+	return len(d.Objects) == 0
+}
+
+// CSVDiff summarizes the changes to the ClusterServiceVersion that are most
+// relevant when reviewing an upgrade.
+type CSVDiff struct {
+	Version             Change[string]             `json:"version"`
+	Replaces            Change[string]             `json:"replaces"`
+	AddedSkips          []string                   `json:"addedSkips,omitempty"`
+	RemovedSkips        []string                   `json:"removedSkips,omitempty"`
+	AddedInstallModes   []v1alpha1.InstallModeType `json:"addedInstallModes,omitempty"`
+	RemovedInstallModes []v1alpha1.InstallModeType `json:"removedInstallModes,omitempty"`
+	AddedImages         []string                   `json:"addedImages,omitempty"`
+	RemovedImages       []string                   `json:"removedImages,omitempty"`
+	AddedPermissions    []Permission               `json:"addedPermissions,omitempty"`
+	RemovedPermissions  []Permission               `json:"removedPermissions,omitempty"`
+}
+
+type Change[T comparable] struct {
+	From T `json:"from"`
+	To   T `json:"to"`
+}
+
+func (c Change[T]) Changed() bool {
+	return c.From != c.To
+}
+
+// Permission is a single verb granted on a single resource to a service
+// account by a CSV.
+type Permission struct {
+	ServiceAccount string `json:"serviceAccount"`
+	// Cluster is true if the permission comes from the CSV's
+	// clusterPermissions.
+	Cluster        bool   `json:"cluster"`
+	APIGroup       string `json:"apiGroup,omitempty"`
+	Resource       string `json:"resource,omitempty"`
+	ResourceNames  string `json:"resourceNames,omitempty"`
+	NonResourceURL string `json:"nonResourceURL,omitempty"`
+	Verb           string `json:"verb"`
+}
+
+func (p Permission) String() string {
+	scope := "namespace"
+	if p.Cluster {
+		scope = "cluster"
+	}
+	target := p.NonResourceURL
+	if target == "" {
+		target = p.Resource
+		if p.APIGroup != "" {
+			target = fmt.Sprintf("%s.%s", p.Resource, p.APIGroup)
+		}
+		if p.ResourceNames != "" {
+			target = fmt.Sprintf("%s [%s]", target, p.ResourceNames)
+		}
+	}
+	return fmt.Sprintf("%s (%s): %s %s", p.ServiceAccount, scope, p.Verb, target)
+}
+
+func comparePermissions(a, b Permission) int {
+	return cmp.Or(
+		cmp.Compare(a.ServiceAccount, b.ServiceAccount),
+		compareBool(a.Cluster, b.Cluster),
+		cmp.Compare(a.APIGroup, b.APIGroup),
+		cmp.Compare(a.Resource, b.Resource),
+		cmp.Compare(a.ResourceNames, b.ResourceNames),
+		cmp.Compare(a.NonResourceURL, b.NonResourceURL),
+		cmp.Compare(a.Verb, b.Verb),
+	)
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// ObjectDiff describes an object that was added, removed, or changed. For
+// changed objects, Fields lists each changed field.
+type ObjectDiff struct {
+	Group  string     `json:"group,omitempty"`
+	Kind   string     `json:"kind"`
+	Name   string     `json:"name"`
+	Change ChangeType `json:"change"`
+	// FromName is set if the object was renamed. This is only the case for
+	// the CSV, whose name usually changes with every version.
+	FromName string      `json:"fromName,omitempty"`
+	Fields   []FieldDiff `json:"fields,omitempty"`
+}
+
+func (d ObjectDiff) String() string {
+	gk := gkn{Name: d.Name}
+	gk.Group, gk.Kind = d.Group, d.Kind
+	return gk.String()
+}
+
+// FieldDiff describes a changed field. From is unset if the field was added,
+// and To is unset if the field was removed.
+type FieldDiff struct {
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+// Diff compares the from and to bundles. Objects are matched by group, kind,
+// and name, except for the CSV, which is matched regardless of name.
+func Diff(from, to *Bundle) (BundleDiff, error) {
+	fromObjs, err := diffObjects(from)
+	if err != nil {
+		return BundleDiff{}, fmt.Errorf("bundle %s: %v", from.ID(), err)
+	}
+	toObjs, err := diffObjects(to)
+	if err != nil {
+		return BundleDiff{}, fmt.Errorf("bundle %s: %v", to.ID(), err)
+	}
+
+	var objects []ObjectDiff
+	for _, key := range sortedKeys(fromObjs, toObjs) {
+		fromObj, inFrom := fromObjs[key]
+		toObj, inTo := toObjs[key]
+		d := ObjectDiff{Group: key.Group, Kind: key.Kind, Name: key.Name}
+		switch {
+		case !inFrom:
+			d.Name = toObj.name
+			d.Change = ChangeTypeAdded
+		case !inTo:
+			d.Name = fromObj.name
+			d.Change = ChangeTypeRemoved
+		default:
+			d.Name = toObj.name
+			if fromObj.name != toObj.name {
+				d.FromName = fromObj.name
+			}
+			d.Fields = diffFields("", fromObj.content, toObj.content)
+			if len(d.Fields) == 0 {
+				continue
+			}
+			d.Change = ChangeTypeChanged
+		}
+		objects = append(objects, d)
+	}
+
+	return BundleDiff{
+		From:    from.ID(),
+		To:      to.ID(),
+		CSV:     diffCSVs(from.manifests.CSV().Value(), to.manifests.CSV().Value()),
+		Objects: objects,
+	}, nil
+}
+
+type diffObject struct {
+	name    string
+	content map[string]any
+}
+
+func diffObjects(b *Bundle) (map[gkn]diffObject, error) {
+	objs := map[gkn]diffObject{}
+	for f := range b.manifests.All() {
+		obj := f.Value()
+		key := gkn{
+			GroupKind: obj.GetObjectKind().GroupVersionKind().GroupKind(),
+			Name:      client.ObjectKeyFromObject(obj).Name,
+		}
+		if _, ok := obj.(*v1alpha1.ClusterServiceVersion); ok {
+			key.Name = ""
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %v", key, err)
+		}
+		objs[key] = diffObject{name: obj.GetName(), content: content}
+	}
+	return objs, nil
+}
+
+func sortedKeys[V any](a, b map[gkn]V) []gkn {
+	keys := sets.New[gkn]()
+	for k := range a {
+		keys.Insert(k)
+	}
+	for k := range b {
+		keys.Insert(k)
+	}
+	return slices.SortedFunc(maps.Keys(keys), func(a, b gkn) int {
+		return cmp.Or(
+			cmp.Compare(a.Group, b.Group),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+}
+
+var simplePathSegment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func fieldPath(parent, key string) string {
+	if !simplePathSegment.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", parent, key)
+	}
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// diffFields recursively compares from and to. Lists whose elements are all
+// objects with unique "name" fields are compared by name rather than by
+// index, so that inserting an element (e.g. a new CRD version) does not
+// report every following element as changed.
+func diffFields(path string, from, to any) []FieldDiff {
+	switch fromV := from.(type) {
+	case map[string]any:
+		toV, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		var diffs []FieldDiff
+		for _, key := range sortedMapKeys(fromV, toV) {
+			fromField, inFrom := fromV[key]
+			toField, inTo := toV[key]
+			switch {
+			case !inFrom:
+				diffs = append(diffs, FieldDiff{Path: fieldPath(path, key), To: toField})
+			case !inTo:
+				diffs = append(diffs, FieldDiff{Path: fieldPath(path, key), From: fromField})
+			default:
+				diffs = append(diffs, diffFields(fieldPath(path, key), fromField, toField)...)
+			}
+		}
+		return diffs
+	case []any:
+		toV, ok := to.([]any)
+		if !ok {
+			break
+		}
+		fromNamed, fromOK := namedElements(fromV)
+		toNamed, toOK := namedElements(toV)
+		if fromOK && toOK {
+			var diffs []FieldDiff
+			for _, name := range sortedMapKeys(fromNamed, toNamed) {
+				elemPath := fmt.Sprintf("%s[name=%s]", path, name)
+				fromElem, inFrom := fromNamed[name]
+				toElem, inTo := toNamed[name]
+				switch {
+				case !inFrom:
+					diffs = append(diffs, FieldDiff{Path: elemPath, To: toElem})
+				case !inTo:
+					diffs = append(diffs, FieldDiff{Path: elemPath, From: fromElem})
+				default:
+					diffs = append(diffs, diffFields(elemPath, fromElem, toElem)...)
+				}
+			}
+			return diffs
+		}
+		var diffs []FieldDiff
+		for i := range max(len(fromV), len(toV)) {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(fromV):
+				diffs = append(diffs, FieldDiff{Path: elemPath, To: toV[i]})
+			case i >= len(toV):
+				diffs = append(diffs, FieldDiff{Path: elemPath, From: fromV[i]})
+			default:
+				diffs = append(diffs, diffFields(elemPath, fromV[i], toV[i])...)
+			}
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, From: from, To: to}}
+}
+
+func namedElements(list []any) (map[string]any, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	named := make(map[string]any, len(list))
+	for _, elem := range list {
+		obj, ok := elem.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, dup := named[name]; dup {
+			return nil, false
+		}
+		named[name] = elem
+	}
+	return named, true
+}
+
+func sortedMapKeys(a, b map[string]any) []string {
+	keys := sets.New[string]()
+	for k := range a {
+		keys.Insert(k)
+	}
+	for k := range b {
+		keys.Insert(k)
+	}
+	return sets.List(keys)
+}
+
+func diffCSVs(from, to *v1alpha1.ClusterServiceVersion) CSVDiff {
+	d := CSVDiff{
+		Version:  Change[string]{From: from.Spec.Version.String(), To: to.Spec.Version.String()},
+		Replaces: Change[string]{From: from.Spec.Replaces, To: to.Spec.Replaces},
+	}
+	d.AddedSkips, d.RemovedSkips = diffSets(sets.New(from.Spec.Skips...), sets.New(to.Spec.Skips...), strings.Compare)
+	d.AddedInstallModes, d.RemovedInstallModes = diffSets(supportedInstallModes(from), supportedInstallModes(to), cmp.Compare[v1alpha1.InstallModeType])
+	d.AddedImages, d.RemovedImages = diffSets(csvImages(from), csvImages(to), strings.Compare)
+	d.AddedPermissions, d.RemovedPermissions = diffSets(csvPermissions(from), csvPermissions(to), comparePermissions)
+	return d
+}
+
+func diffSets[T comparable](from, to sets.Set[T], compare func(a, b T) int) (added, removed []T) {
+	added = slices.SortedFunc(maps.Keys(to.Difference(from)), compare)
+	removed = slices.SortedFunc(maps.Keys(from.Difference(to)), compare)
+	return added, removed
+}
+
+func supportedInstallModes(csv *v1alpha1.ClusterServiceVersion) sets.Set[v1alpha1.InstallModeType] {
+	installModes := sets.New[v1alpha1.InstallModeType]()
+	for _, installMode := range csv.Spec.InstallModes {
+		if installMode.Supported {
+			installModes.Insert(installMode.Type)
+		}
+	}
+	return installModes
+}
+
+// csvImages returns the related images of csv and the images of the
+// containers of its deployments.
+func csvImages(csv *v1alpha1.ClusterServiceVersion) sets.Set[string] {
+	images := sets.New[string]()
+	for _, relatedImage := range csv.Spec.RelatedImages {
+		images.Insert(relatedImage.Image)
+	}
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+			images.Insert(c.Image)
+		}
+	}
+	return images
+}
+
+// csvPermissions flattens the permissions and cluster permissions of csv into
+// individual verbs granted on individual resources.
+func csvPermissions(csv *v1alpha1.ClusterServiceVersion) sets.Set[Permission] {
+	perms := sets.New[Permission]()
+	add := func(cluster bool, stratPerms []v1alpha1.StrategyDeploymentPermissions) {
+		for _, sp := range stratPerms {
+			for _, rule := range sp.Rules {
+				for _, p := range flattenPolicyRule(rule) {
+					p.ServiceAccount = sp.ServiceAccountName
+					p.Cluster = cluster
+					perms.Insert(p)
+				}
+			}
+		}
+	}
+	add(false, csv.Spec.InstallStrategy.StrategySpec.Permissions)
+	add(true, csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions)
+	return perms
+}
+
+func flattenPolicyRule(rule rbacv1.PolicyRule) []Permission {
+	var perms []Permission
+	resourceNames := strings.Join(rule.ResourceNames, ",")
+	for _, verb := range rule.Verbs {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				perms = append(perms, Permission{APIGroup: group, Resource: resource, ResourceNames: resourceNames, Verb: verb})
+			}
+		}
+		for _, url := range rule.NonResourceURLs {
+			perms = append(perms, Permission{NonResourceURL: url, Verb: verb})
+		}
+	}
+	return perms
+}
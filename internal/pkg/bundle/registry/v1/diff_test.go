@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func Test_Diff(t *testing.T) {
+	from, err := NewBundleFSLoader(validBundleFS()).Load()
+	require.NoError(t, err)
+
+	toFS := validBundleFS()
+	toFS["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.3.0
+spec:
+  version: "1.3.0"
+  replaces: example.v1.2.3
+  skips:
+    - example.v1.2.2
+  installModes:
+    - type: AllNamespaces
+      supported: true
+  relatedImages:
+    - name: operator
+      image: quay.io/example/operator:v1.3.0
+  install:
+    strategy: deployment
+    spec:
+      clusterPermissions:
+        - serviceAccountName: operator
+          rules:
+            - apiGroups: [""]
+              resources: [secrets]
+              verbs: [get, list]
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+      - name: resources.group.example.com
+        version: v1
+        kind: Resource
+`)}
+	toFS["manifests/crd.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resources.group.example.com
+spec:
+  names:
+    kind: Resource
+  versions:
+    - name: v1
+    - name: v1alpha1
+      deprecated: true
+`)}
+	delete(toFS, "manifests/secret.yaml")
+	toFS["manifests/configmap.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example-config
+`)}
+	to, err := NewBundleFSLoader(toFS).Load()
+	require.NoError(t, err)
+
+	d, err := Diff(from, to)
+	require.NoError(t, err)
+	require.False(t, d.Empty())
+	require.Equal(t, "example.v1.2.3", d.From)
+	require.Equal(t, "example.v1.3.0", d.To)
+
+	require.Equal(t, CSVDiff{
+		Version:           Change[string]{From: "1.2.3", To: "1.3.0"},
+		Replaces:          Change[string]{From: "", To: "example.v1.2.3"},
+		AddedSkips:        []string{"example.v1.2.2"},
+		AddedInstallModes: []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeAllNamespaces},
+		AddedImages:       []string{"quay.io/example/operator:v1.3.0"},
+		AddedPermissions: []Permission{
+			{ServiceAccount: "operator", Cluster: true, Resource: "secrets", Verb: "get"},
+			{ServiceAccount: "operator", Cluster: true, Resource: "secrets", Verb: "list"},
+		},
+	}, d.CSV)
+
+	type objectChange struct {
+		object string
+		change ChangeType
+	}
+	var changes []objectChange
+	for _, o := range d.Objects {
+		changes = append(changes, objectChange{o.String(), o.Change})
+	}
+	require.Equal(t, []objectChange{
+		{"ConfigMap/example-config", ChangeTypeAdded},
+		{"Secret/example-secret", ChangeTypeRemoved},
+		{"CustomResourceDefinition.apiextensions.k8s.io/resources.group.example.com", ChangeTypeChanged},
+		{"ClusterServiceVersion.operators.coreos.com/example.v1.3.0", ChangeTypeChanged},
+	}, changes)
+
+	crdDiff := d.Objects[2]
+	require.Equal(t, []FieldDiff{
+		{Path: "spec.versions[name=v1]", To: map[string]any{"name": "v1", "served": false, "storage": false}},
+		{Path: "spec.versions[name=v1alpha1].deprecated", To: true},
+	}, crdDiff.Fields)
+
+	csvDiff := d.Objects[3]
+	require.Equal(t, "example.v1.2.3", csvDiff.FromName)
+	require.Contains(t, csvDiff.Fields, FieldDiff{Path: "metadata.name", From: "example.v1.2.3", To: "example.v1.3.0"})
+
+	same, err := Diff(from, from)
+	require.NoError(t, err)
+	require.True(t, same.Empty())
+}
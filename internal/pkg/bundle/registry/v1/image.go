@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type bundleImageLoader struct {
+	ctx     context.Context
+	fetcher content.Fetcher
+	desc    ocispec.Descriptor
+}
+
+// NewBundleImageLoader returns a loader for the bundle image manifest described
+// by desc. The image layers are unpacked into an in-memory filesystem, which is
+// loaded with NewBundleFSLoader.
+func NewBundleImageLoader(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) BundleLoader {
+	return &bundleImageLoader{ctx: ctx, fetcher: fetcher, desc: desc}
+}
+
+func (l *bundleImageLoader) Load() (*Bundle, error) {
+	manifest, err := ocilayout.FetchManifest(l.ctx, l.fetcher, l.desc)
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := ocilayout.LayersFS(l.ctx, l.fetcher, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return NewBundleFSLoader(fsys).Load()
+}
+
+// ImageID returns the ID and the image name and tag of the bundle image
+// manifest described by desc, in the same format used by Bundle.ID and
+// Bundle.MarshalOCI. The package name is read from the image config labels and
+// the version is read from the bundle's CSV.
+func ImageID(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (string, string, error) {
+	manifest, err := ocilayout.FetchManifest(ctx, fetcher, desc)
+	if err != nil {
+		return "", "", err
+	}
+	cfg, err := ocilayout.FetchConfig(ctx, fetcher, manifest)
+	if err != nil {
+		return "", "", err
+	}
+	packageName := cfg.Config.Labels[annotationPackage]
+	if packageName == "" {
+		return "", "", fmt.Errorf("image config has no %q label", annotationPackage)
+	}
+
+	b, err := NewBundleImageLoader(ctx, fetcher, desc).Load()
+	if err != nil {
+		return "", "", err
+	}
+	return bundleID(packageName, b.tag()), bundleImageNameTag(packageName, b.tag()), nil
+}
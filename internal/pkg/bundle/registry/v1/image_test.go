@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func Test_BundleImageLoader_Load(t *testing.T) {
+	expected, err := NewBundleFSLoader(validBundleFS()).Load()
+	require.NoError(t, err)
+
+	target := memory.New()
+	desc, err := expected.MarshalOCI(t.Context(), target)
+	require.NoError(t, err)
+
+	actual, err := NewBundleImageLoader(t.Context(), target, desc).Load()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	id, imageNameTag, err := ImageID(t.Context(), target, desc)
+	require.NoError(t, err)
+	require.Equal(t, "example.v1.2.3", id)
+	require.Equal(t, "example:1.2.3", imageNameTag)
+}
+
+func validBundleFS() fstest.MapFS {
+	return fstest.MapFS{
+		"manifests/csv.yaml": &fstest.MapFile{Data: []byte(`
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+`)},
+		"manifests/crd.yaml": &fstest.MapFile{Data: []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resources.group.example.com
+spec:
+  names:
+    kind: Resource
+  versions:
+    - name: v1alpha1
+`)},
+		"manifests/secret.yaml": &fstest.MapFile{Data: []byte(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: example-secret
+`)},
+		"metadata/annotations.yaml": &fstest.MapFile{Data: []byte(`
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: example
+`)},
+	}
+}
@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+)
+
+// relatedImageEnvPrefix is the prefix of the names of container env vars that
+// reference related images, e.g. RELATED_IMAGE_OPERAND.
+const relatedImageEnvPrefix = "RELATED_IMAGE_"
+
+// deploymentImages returns the images referenced by the install strategy
+// deployments of csv: container and init container images, and the values of
+// RELATED_IMAGE_* env vars.
+func deploymentImages(csv *v1alpha1.ClusterServiceVersion) sets.Set[string] {
+	images := sets.New[string]()
+	for _, di := range namedDeploymentImages(csv) {
+		images.Insert(di.image)
+	}
+	return images
+}
+
+// PinImages resolves every image referenced by the CSV's deployments (see
+// deploymentImages) and related images to a digest using resolver, and
+// rewrites the CSV to reference the images by digest. It returns the images
+// that were pinned; images that are already referenced by digest are left
+// unchanged.
+func (b *Bundle) PinImages(ctx context.Context, resolver imageresolver.Resolver) ([]imageresolver.PinnedImage, error) {
+	csv := b.manifests.CSV().Value()
+	images := deploymentImages(csv)
+	for _, relatedImage := range csv.Spec.RelatedImages {
+		images.Insert(relatedImage.Image)
+	}
+	images.Delete("")
+
+	var pinnedImages []imageresolver.PinnedImage
+	pinned := map[string]string{}
+	for _, image := range sets.List(images) {
+		ref, err := imageresolver.Pin(ctx, resolver, image)
+		if err != nil {
+			return nil, err
+		}
+		if ref == image {
+			continue
+		}
+		pinned[image] = ref
+		pinnedImages = append(pinnedImages, imageresolver.PinnedImage{Original: image, Pinned: ref})
+	}
+	if len(pinned) == 0 {
+		return nil, nil
+	}
+
+	if err := b.updateCSV(func(csv *unstructured.Unstructured) error {
+		return mapCSVImages(csv, func(image string) string {
+			if ref, ok := pinned[image]; ok {
+				return ref
+			}
+			return image
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to pin images: %w", err)
+	}
+	return pinnedImages, nil
+}
+
+// updateCSV updates the CSV of b using fn. The CSV is updated in its
+// unstructured form so that fields unknown to the CSV type are preserved.
+func (b *Bundle) updateCSV(fn func(*unstructured.Unstructured) error) error {
+	csvFile := b.manifests.CSV()
+	var csv unstructured.Unstructured
+	if err := yaml.Unmarshal(csvFile.Data(), &csv.Object); err != nil {
+		return err
+	}
+	if err := fn(&csv); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(csv.Object)
+	if err != nil {
+		return err
+	}
+	mf, err := newManifestFileFromReader(bytes.NewReader(data), csvFile.Name())
+	if err != nil {
+		return err
+	}
+	csvObj, ok := mf.Value()[0].(*v1alpha1.ClusterServiceVersion)
+	if len(mf.Value()) != 1 || !ok {
+		return fmt.Errorf("updated %s is not a single ClusterServiceVersion", csvFile.Name())
+	}
+	b.manifests.csv = NewPrecomputedFile(csvFile.Name(), mf.Data(), csvObj)
+	return nil
+}
+
+// mapCSVImages replaces each image referenced by the unstructured csv (see
+// deploymentImages, plus its related images) with the result of fn.
+func mapCSVImages(csv *unstructured.Unstructured, fn func(string) string) error {
+	mapImage := func(obj map[string]any, field string) {
+		if image, ok := obj[field].(string); ok && image != "" {
+			obj[field] = fn(image)
+		}
+	}
+	if err := mapNestedSlice(csv.Object, func(dep map[string]any) error {
+		for _, containers := range []string{"initContainers", "containers"} {
+			if err := mapNestedSlice(dep, func(c map[string]any) error {
+				mapImage(c, "image")
+				return mapNestedSlice(c, func(env map[string]any) error {
+					if name, _ := env["name"].(string); strings.HasPrefix(name, relatedImageEnvPrefix) {
+						mapImage(env, "value")
+					}
+					return nil
+				}, "env")
+			}, "spec", "template", "spec", containers); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, "spec", "install", "spec", "deployments"); err != nil {
+		return err
+	}
+	return mapNestedSlice(csv.Object, func(relatedImage map[string]any) error {
+		mapImage(relatedImage, "image")
+		return nil
+	}, "spec", "relatedImages")
+}
+
+// mapNestedSlice applies fn to each object in the slice at the given fields
+// of obj, if there is one.
+func mapNestedSlice(obj map[string]any, fn func(map[string]any) error, fields ...string) error {
+	items, found, err := unstructured.NestedSlice(obj, fields...)
+	if err != nil || !found {
+		return err
+	}
+	for _, item := range items {
+		itemObj, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", strings.Join(fields, "."), item)
+		}
+		if err := fn(itemObj); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedSlice(obj, items, fields...)
+}
+
+const checkManifestsCompleteRelatedImages = "manifests/complete-related-images"
+
+// deploymentImage is an image referenced by an install strategy deployment,
+// along with the name it is given in the CSV's related images.
+type deploymentImage struct {
+	name  string
+	image string
+}
+
+// namedDeploymentImages returns the images referenced by the install
+// strategy deployments of csv (see deploymentImages) in the order they
+// appear. Container images are named after their containers, and images of
+// RELATED_IMAGE_* env vars after their env var, e.g. "operand" for
+// RELATED_IMAGE_OPERAND.
+func namedDeploymentImages(csv *v1alpha1.ClusterServiceVersion) []deploymentImage {
+	var images []deploymentImage
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+			if c.Image != "" {
+				images = append(images, deploymentImage{name: c.Name, image: c.Image})
+			}
+			for _, env := range c.Env {
+				if name, ok := strings.CutPrefix(env.Name, relatedImageEnvPrefix); ok && env.Value != "" {
+					images = append(images, deploymentImage{
+						name:  strings.ReplaceAll(strings.ToLower(name), "_", "-"),
+						image: env.Value,
+					})
+				}
+			}
+		}
+	}
+	return images
+}
+
+// relatedImages returns the complete related images of csv: its existing
+// related images, without duplicate images, followed by every image that is
+// referenced by its deployments but missing from its related images. It also
+// returns a warning for every conflict it resolved, i.e. an image listed
+// under several names or a name used for several images.
+func relatedImages(csv *v1alpha1.ClusterServiceVersion) ([]v1alpha1.RelatedImage, []string) {
+	var (
+		related  []v1alpha1.RelatedImage
+		warnings []string
+	)
+	names := map[string]string{}
+	images := map[string]string{}
+	for _, ri := range csv.Spec.RelatedImages {
+		if name, ok := images[ri.Image]; ok {
+			warnings = append(warnings, fmt.Sprintf("related image %s is listed as both %q and %q; keeping %q", ri.Image, name, ri.Name, name))
+			continue
+		}
+		if image, ok := names[ri.Name]; ok {
+			warnings = append(warnings, fmt.Sprintf("related image name %q is used for both %s and %s", ri.Name, image, ri.Image))
+		}
+		names[ri.Name] = ri.Image
+		images[ri.Image] = ri.Name
+		related = append(related, ri)
+	}
+	for _, di := range namedDeploymentImages(csv) {
+		if _, ok := images[di.image]; ok {
+			continue
+		}
+		name := di.name
+		if image, ok := names[name]; ok {
+			for i := 2; ; i++ {
+				name = fmt.Sprintf("%s-%d", di.name, i)
+				if _, ok := names[name]; !ok {
+					break
+				}
+			}
+			warnings = append(warnings, fmt.Sprintf("related image name %q is already used for %s; naming %s %q", di.name, image, di.image, name))
+		}
+		names[name] = di.image
+		images[di.image] = name
+		related = append(related, v1alpha1.RelatedImage{Name: name, Image: di.image})
+	}
+	return related, warnings
+}
+
+// PopulateRelatedImages completes the CSV's related images with every image
+// referenced by its install strategy deployments, so that tools that mirror
+// the images of bundles find all of them. Existing related images are kept,
+// and new ones are given stable names derived from their containers or env
+// vars. It returns a warning for every naming conflict it resolved.
+func (b *Bundle) PopulateRelatedImages() ([]string, error) {
+	csv := b.manifests.CSV().Value()
+	related, warnings := relatedImages(csv)
+	if slices.Equal(related, csv.Spec.RelatedImages) {
+		return warnings, nil
+	}
+
+	items := make([]any, 0, len(related))
+	for _, ri := range related {
+		items = append(items, map[string]any{"name": ri.Name, "image": ri.Image})
+	}
+	if err := b.updateCSV(func(csv *unstructured.Unstructured) error {
+		return unstructured.SetNestedSlice(csv.Object, items, "spec", "relatedImages")
+	}); err != nil {
+		return nil, fmt.Errorf("failed to populate related images: %w", err)
+	}
+	return warnings, nil
+}
+
+// ValidateRelatedImages returns a ValidationError with a warning diagnostic
+// for every image that is referenced by the CSV's install strategy
+// deployments but missing from its related images.
+func (b *Bundle) ValidateRelatedImages() error {
+	csvFile := b.manifests.CSV()
+	listed := sets.New[string]()
+	for _, ri := range csvFile.Value().Spec.RelatedImages {
+		listed.Insert(ri.Image)
+	}
+	var diagnostics []Diagnostic
+	for _, image := range sets.List(deploymentImages(csvFile.Value()).Difference(listed)) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Check:    checkManifestsCompleteRelatedImages,
+			Severity: SeverityWarning,
+			File:     filepath.Join(manifestsDirectory, csvFile.Name()),
+			Message:  fmt.Sprintf("image %s is referenced by the install strategy but missing from spec.relatedImages", image),
+		})
+	}
+	return newValidationError(diagnostics)
+}
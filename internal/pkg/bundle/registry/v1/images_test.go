@@ -0,0 +1,256 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+)
+
+const testImagesCSV = `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  relatedImages:
+    - name: operand
+      image: quay.io/example/operand:v1
+    - name: pinned
+      image: quay.io/example/pinned@sha256:1111111111111111111111111111111111111111111111111111111111111111
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+        - name: operator
+          spec:
+            template:
+              spec:
+                initContainers:
+                  - name: init
+                    image: quay.io/example/init:v1
+                containers:
+                  - name: operator
+                    image: quay.io/example/operator:v1.2.3
+                    env:
+                      - name: RELATED_IMAGE_OPERAND
+                        value: quay.io/example/operand:v1
+                      - name: OTHER
+                        value: quay.io/example/other:v1
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+`
+
+// testResolver resolves each image to the sha256 digest of its reference.
+type testResolver struct {
+	missing string
+}
+
+func (r testResolver) Resolve(_ context.Context, ref string) (digest.Digest, error) {
+	if ref == r.missing {
+		return "", fmt.Errorf("%s not found", ref)
+	}
+	return digest.FromString(ref), nil
+}
+
+func Test_Bundle_PinImages(t *testing.T) {
+	pinned := func(repository, ref string) string {
+		return repository + "@" + digest.FromString(ref).String()
+	}
+	tests := []struct {
+		name        string
+		resolver    imageresolver.Resolver
+		expected    []imageresolver.PinnedImage
+		expectedErr string
+	}{
+		{
+			name:     "pins tagged images",
+			resolver: testResolver{},
+			expected: []imageresolver.PinnedImage{
+				{Original: "quay.io/example/init:v1", Pinned: pinned("quay.io/example/init", "quay.io/example/init:v1")},
+				{Original: "quay.io/example/operand:v1", Pinned: pinned("quay.io/example/operand", "quay.io/example/operand:v1")},
+				{Original: "quay.io/example/operator:v1.2.3", Pinned: pinned("quay.io/example/operator", "quay.io/example/operator:v1.2.3")},
+			},
+		},
+		{
+			name:        "unresolvable image",
+			resolver:    testResolver{missing: "quay.io/example/init:v1"},
+			expectedErr: `failed to resolve image "quay.io/example/init:v1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := validBundleFS()
+			fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(testImagesCSV)}
+			b, err := NewBundleFSLoader(fsys).Load()
+			require.NoError(t, err)
+
+			actual, err := b.PinImages(t.Context(), tt.resolver)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+
+			csv := b.Manifests().CSV().Value()
+			podSpec := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+			require.Equal(t, tt.expected[0].Pinned, podSpec.InitContainers[0].Image)
+			require.Equal(t, tt.expected[2].Pinned, podSpec.Containers[0].Image)
+			require.Equal(t, tt.expected[1].Pinned, podSpec.Containers[0].Env[0].Value)
+			require.Equal(t, "quay.io/example/other:v1", podSpec.Containers[0].Env[1].Value)
+			require.Equal(t, tt.expected[1].Pinned, csv.Spec.RelatedImages[0].Image)
+			require.Equal(t, "quay.io/example/pinned@sha256:1111111111111111111111111111111111111111111111111111111111111111", csv.Spec.RelatedImages[1].Image)
+
+			// The rewritten CSV is what gets marshaled.
+			reloaded, err := NewBundleFSLoader(b.toFS()).Load()
+			require.NoError(t, err)
+			require.Equal(t, csv.Spec, reloaded.Manifests().CSV().Value().Spec)
+		})
+	}
+}
+
+func Test_Bundle_PopulateRelatedImages(t *testing.T) {
+	tests := []struct {
+		name             string
+		relatedImages    string
+		expected         []v1alpha1.RelatedImage
+		expectedWarnings []string
+	}{
+		{
+			name: "no related images",
+			expected: []v1alpha1.RelatedImage{
+				{Name: "init", Image: "quay.io/example/init:v1"},
+				{Name: "operator", Image: "quay.io/example/operator:v1.2.3"},
+				{Name: "operand", Image: "quay.io/example/operand:v1"},
+			},
+		},
+		{
+			name: "existing related images are kept",
+			relatedImages: `
+    - name: custom-operand
+      image: quay.io/example/operand:v1
+    - name: extra
+      image: quay.io/example/extra:v1`,
+			expected: []v1alpha1.RelatedImage{
+				{Name: "custom-operand", Image: "quay.io/example/operand:v1"},
+				{Name: "extra", Image: "quay.io/example/extra:v1"},
+				{Name: "init", Image: "quay.io/example/init:v1"},
+				{Name: "operator", Image: "quay.io/example/operator:v1.2.3"},
+			},
+		},
+		{
+			name: "conflicts",
+			relatedImages: `
+    - name: operator
+      image: quay.io/example/operator:v1.0.0
+    - name: duplicate
+      image: quay.io/example/operator:v1.0.0`,
+			expected: []v1alpha1.RelatedImage{
+				{Name: "operator", Image: "quay.io/example/operator:v1.0.0"},
+				{Name: "init", Image: "quay.io/example/init:v1"},
+				{Name: "operator-2", Image: "quay.io/example/operator:v1.2.3"},
+				{Name: "operand", Image: "quay.io/example/operand:v1"},
+			},
+			expectedWarnings: []string{
+				`related image quay.io/example/operator:v1.0.0 is listed as both "operator" and "duplicate"; keeping "operator"`,
+				`related image name "operator" is already used for quay.io/example/operator:v1.0.0; naming quay.io/example/operator:v1.2.3 "operator-2"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := validBundleFS()
+			fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(testRelatedImagesCSV(tt.relatedImages))}
+			b, err := NewBundleFSLoader(fsys).Load()
+			require.NoError(t, err)
+			require.Error(t, b.ValidateRelatedImages())
+
+			warnings, err := b.PopulateRelatedImages()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedWarnings, warnings)
+			require.Equal(t, tt.expected, b.Manifests().CSV().Value().Spec.RelatedImages)
+			require.NoError(t, b.ValidateRelatedImages())
+
+			// Populating is idempotent.
+			warnings, err = b.PopulateRelatedImages()
+			require.NoError(t, err)
+			require.Empty(t, warnings)
+			require.Equal(t, tt.expected, b.Manifests().CSV().Value().Spec.RelatedImages)
+		})
+	}
+}
+
+func Test_Bundle_ValidateRelatedImages(t *testing.T) {
+	fsys := validBundleFS()
+	fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(testRelatedImagesCSV(`
+    - name: operand
+      image: quay.io/example/operand:v1`))}
+	b, err := NewBundleFSLoader(fsys).Load()
+	require.NoError(t, err)
+
+	err = b.ValidateRelatedImages()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []Diagnostic{
+		{
+			Check:    checkManifestsCompleteRelatedImages,
+			Severity: SeverityWarning,
+			File:     "manifests/csv.yaml",
+			Message:  "image quay.io/example/init:v1 is referenced by the install strategy but missing from spec.relatedImages",
+		},
+		{
+			Check:    checkManifestsCompleteRelatedImages,
+			Severity: SeverityWarning,
+			File:     "manifests/csv.yaml",
+			Message:  "image quay.io/example/operator:v1.2.3 is referenced by the install strategy but missing from spec.relatedImages",
+		},
+	}, validationErr.Diagnostics)
+}
+
+func testRelatedImagesCSV(relatedImages string) string {
+	if relatedImages == "" {
+		relatedImages = " []"
+	}
+	return `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  relatedImages:` + relatedImages + `
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+        - name: operator
+          spec:
+            template:
+              spec:
+                initContainers:
+                  - name: init
+                    image: quay.io/example/init:v1
+                containers:
+                  - name: operator
+                    image: quay.io/example/operator:v1.2.3
+                    env:
+                      - name: RELATED_IMAGE_OPERAND
+                        value: quay.io/example/operand:v1
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+`
+}
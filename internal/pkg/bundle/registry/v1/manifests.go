@@ -28,25 +28,26 @@ import (
 	"github.com/operator-framework/kpm/internal/pkg/bundle/registry/internal"
 )
 
-type manifests struct {
+// Manifests are the validated files of a bundle's manifests directory.
+type Manifests struct {
 	csv    File[*v1alpha1.ClusterServiceVersion]
 	crds   []File[*apiextensionsv1.CustomResourceDefinition]
 	others []File[client.Object]
 }
 
-func (m *manifests) CSV() File[*v1alpha1.ClusterServiceVersion] {
+func (m *Manifests) CSV() File[*v1alpha1.ClusterServiceVersion] {
 	return m.csv
 }
 
-func (m *manifests) CRDs() []File[*apiextensionsv1.CustomResourceDefinition] {
+func (m *Manifests) CRDs() []File[*apiextensionsv1.CustomResourceDefinition] {
 	return m.crds
 }
 
-func (m *manifests) Others() []File[client.Object] {
+func (m *Manifests) Others() []File[client.Object] {
 	return m.others
 }
 
-func (m *manifests) All() iter.Seq[File[client.Object]] {
+func (m *Manifests) All() iter.Seq[File[client.Object]] {
 	return func(yield func(File[client.Object]) bool) {
 		if !yield(toObjectFile(m.csv)) {
 			return
@@ -68,7 +69,7 @@ func toObjectFile[T client.Object](in File[T]) File[client.Object] {
 	return NewPrecomputedFile[client.Object](in.Name(), in.Data(), in.Value())
 }
 
-func (m *manifests) addToFS(fsys fstest.MapFS) {
+func (m *Manifests) addToFS(fsys fstest.MapFS) {
 	for f := range m.All() {
 		path := filepath.Join(manifestsDirectory, f.Name())
 		fsys[path] = &fstest.MapFile{Data: f.Data()}
@@ -76,29 +77,42 @@ func (m *manifests) addToFS(fsys fstest.MapFS) {
 }
 
 type ManifestsLoader interface {
-	Load() (*manifests, error)
+	Load() (*Manifests, error)
 }
 
 type manifestsFSLoader struct {
 	fsys fs.FS
+	// patches are applied to the loaded manifests before they are
+	// validated.
+	patches []Patch
 }
 
-func (m *manifestsFSLoader) Load() (*manifests, error) {
+func (m *manifestsFSLoader) Load() (*Manifests, error) {
 	files, err := m.loadFiles()
 	if err != nil {
 		return nil, err
 	}
+	if len(m.patches) > 0 {
+		if files, err = files.patch(m.patches); err != nil {
+			return nil, err
+		}
+	}
 	return files.toManifests()
 }
 
 func (m *manifestsFSLoader) loadFiles() (manifestFiles, error) {
 	var (
-		files    manifestFiles
-		loadErrs []error
+		files       manifestFiles
+		loadErrs    []error
+		diagnostics []Diagnostic
 	)
 
 	if err := fs.WalkDir(m.fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
+			if path == "." && errors.Is(err, fs.ErrNotExist) {
+				diagnostics = append(diagnostics, Diagnostic{Check: checkManifestsLoad, Severity: SeverityError, Message: fmt.Sprintf("%s directory not found", manifestsDirectory)})
+				return nil
+			}
 			loadErrs = append(loadErrs, err)
 			return nil
 		}
@@ -115,7 +129,7 @@ func (m *manifestsFSLoader) loadFiles() (manifestFiles, error) {
 
 		mf, err := newManifestFileFromReader(f, path)
 		if err != nil {
-			loadErrs = append(loadErrs, err)
+			diagnostics = append(diagnostics, Diagnostic{Check: checkManifestsLoad, Severity: SeverityError, File: filepath.Join(manifestsDirectory, path), Message: err.Error()})
 			return nil
 		}
 		files = append(files, *mf)
@@ -126,16 +140,19 @@ func (m *manifestsFSLoader) loadFiles() (manifestFiles, error) {
 	if err := errors.Join(loadErrs...); err != nil {
 		return nil, fmt.Errorf("failed to load manifests: %v", err)
 	}
+	if err := newValidationError(diagnostics); err != nil {
+		return nil, err
+	}
 	return files, nil
 }
 
 type manifestFiles []File[[]client.Object]
 
-func (m manifestFiles) toManifests() (*manifests, error) {
+func (m manifestFiles) toManifests() (*Manifests, error) {
 	if err := m.validate(); err != nil {
 		return nil, err
 	}
-	var manifests manifests
+	var manifests Manifests
 	for _, mf := range m {
 		if len(mf.Value()) != 1 {
 			panic("validation should have ensured that each manifest file has exactly one object")
@@ -155,24 +172,25 @@ func (m manifestFiles) toManifests() (*manifests, error) {
 	return &manifests, nil
 }
 
+const (
+	checkManifestsLoad                = "manifests/load"
+	checkManifestsNoSubDirectories    = "manifests/no-subdirectories"
+	checkManifestsOneObjectPerFile    = "manifests/one-object-per-file"
+	checkManifestsExactlyOneCSV       = "manifests/exactly-one-csv"
+	checkManifestsUniqueGroupKindName = "manifests/unique-group-kind-name"
+	checkManifestsSupportedKinds      = "manifests/supported-kinds"
+	checkManifestsOwnedAPIs           = "manifests/owned-apis"
+)
+
 func (m manifestFiles) validate() error {
-	var validationErrors []error
-	for _, validationFn := range []func() error{
-		m.validateNoSubDirectories,
-		m.validateOneObjectPerFile,
-		m.validateExactlyOneCSV,
-		m.validateUniqueGroupKindName,
-		m.validateSupportedKinds,
-		m.validateOwnedAPIs,
-	} {
-		if err := validationFn(); err != nil {
-			validationErrors = append(validationErrors, err)
-		}
-	}
-	if err := errors.Join(validationErrors...); err != nil {
-		return fmt.Errorf("invalid registry+v1 manifests: %v", err)
-	}
-	return nil
+	return runChecks(
+		check{checkManifestsNoSubDirectories, m.validateNoSubDirectories},
+		check{checkManifestsOneObjectPerFile, m.validateOneObjectPerFile},
+		check{checkManifestsExactlyOneCSV, m.validateExactlyOneCSV},
+		check{checkManifestsUniqueGroupKindName, m.validateUniqueGroupKindName},
+		check{checkManifestsSupportedKinds, m.validateSupportedKinds},
+		check{checkManifestsOwnedAPIs, m.validateOwnedAPIs},
+	)
 }
 
 func (m manifestFiles) validateNoSubDirectories() error {
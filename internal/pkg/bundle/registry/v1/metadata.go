@@ -20,29 +20,30 @@ import (
 	"github.com/operator-framework/kpm/internal/pkg/bundle/registry/internal"
 )
 
-type metadata struct {
+// Metadata are the validated files of a bundle's metadata directory.
+type Metadata struct {
 	annotationsFile  AnnotationsFile
 	propertiesFile   *PropertiesFile
 	dependenciesFile *DependenciesFile
 }
 
-func (m *metadata) PackageName() string {
+func (m *Metadata) PackageName() string {
 	return m.annotationsFile.Value().Annotations[annotationPackage]
 }
 
-func (m *metadata) Annotations() AnnotationsFile {
+func (m *Metadata) Annotations() AnnotationsFile {
 	return m.annotationsFile
 }
 
-func (m *metadata) Properties() *PropertiesFile {
+func (m *Metadata) Properties() *PropertiesFile {
 	return m.propertiesFile
 }
 
-func (m *metadata) Dependencies() *DependenciesFile {
+func (m *Metadata) Dependencies() *DependenciesFile {
 	return m.dependenciesFile
 }
 
-func (m *metadata) All() iter.Seq[File[any]] {
+func (m *Metadata) All() iter.Seq[File[any]] {
 	return func(yield func(File[any]) bool) {
 		if !yield(toAnyFile(m.annotationsFile)) {
 			return
@@ -60,7 +61,7 @@ func toAnyFile[T any](in File[T]) File[any] {
 	return NewPrecomputedFile[any](in.Name(), in.Data(), in.Value())
 }
 
-func (m *metadata) addToFS(fsys fstest.MapFS) {
+func (m *Metadata) addToFS(fsys fstest.MapFS) {
 	for f := range m.All() {
 		path := filepath.Join(metadataDirectory, f.Name())
 		fsys[path] = &fstest.MapFile{Data: f.Data()}
@@ -91,31 +92,39 @@ type Property struct {
 type Dependency = Property
 
 type MetadataLoader interface {
-	Load() (*metadata, error)
+	Load() (*Metadata, error)
 }
 type metadataFSLoader struct {
 	fsys fs.FS
+	// overrides, if set, are applied to the loaded metadata before it is
+	// validated.
+	overrides *MetadataOverrides
 }
 
-func (m *metadataFSLoader) loadMetadata() (*metadata, error) {
+func (m *metadataFSLoader) loadMetadata() (*Metadata, error) {
 	a, aErr := m.loadAnnotations()
 	p, pErr := m.loadProperties()
 	d, dErr := m.loadDependencies()
-	if err := errors.Join(aErr, pErr, dErr); err != nil {
+	if err := joinValidationErrors(aErr, pErr, dErr); err != nil {
 		return nil, err
 	}
-	return &metadata{
+	return &Metadata{
 		annotationsFile:  *a,
 		propertiesFile:   p,
 		dependenciesFile: d,
 	}, nil
 }
 
-func (m *metadataFSLoader) Load() (*metadata, error) {
+func (m *metadataFSLoader) Load() (*Metadata, error) {
 	metadata, err := m.loadMetadata()
 	if err != nil {
 		return nil, err
 	}
+	if m.overrides != nil {
+		if metadata, err = metadata.override(*m.overrides); err != nil {
+			return nil, err
+		}
+	}
 	if err := metadata.validate(); err != nil {
 		return nil, err
 	}
@@ -131,11 +140,14 @@ const (
 func (m *metadataFSLoader) loadAnnotations() (*AnnotationsFile, error) {
 	data, err := fs.ReadFile(m.fsys, annotationsFileName)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, newMetadataLoadError(annotationsFileName, fmt.Errorf("%s not found", annotationsFileName))
+		}
 		return nil, err
 	}
 	f, err := NewYAMLDataFile[Annotations](annotationsFileName, data)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing %s: %v", annotationsFileName, err)
+		return nil, newMetadataLoadError(annotationsFileName, fmt.Errorf("error parsing %s: %v", annotationsFileName, err))
 	}
 	return f, err
 }
@@ -150,7 +162,7 @@ func (m *metadataFSLoader) loadProperties() (*PropertiesFile, error) {
 	}
 	f, err := NewYAMLDataFile[Properties](propertiesFileName, data)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing %s: %v", propertiesFileName, err)
+		return nil, newMetadataLoadError(propertiesFileName, fmt.Errorf("error parsing %s: %v", propertiesFileName, err))
 	}
 	return f, err
 }
@@ -164,24 +176,33 @@ func (m *metadataFSLoader) loadDependencies() (*DependenciesFile, error) {
 	}
 	f, err := NewYAMLDataFile[Dependencies](dependenciesFileName, data)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing %s: %v", dependenciesFileName, err)
+		return nil, newMetadataLoadError(dependenciesFileName, fmt.Errorf("error parsing %s: %v", dependenciesFileName, err))
 	}
 	return f, err
 }
 
-func (m *metadata) validate() error {
-	validations := []func() error{
-		m.validateAnnotations,
-		m.validateProperties,
-		m.validateDependencies,
-	}
-	var validationErrors []error
-	for _, fn := range validations {
-		if err := fn(); err != nil {
-			validationErrors = append(validationErrors, err)
-		}
-	}
-	return errors.Join(validationErrors...)
+func newMetadataLoadError(fileName string, err error) error {
+	return newValidationError([]Diagnostic{{
+		Check:    checkMetadataLoad,
+		Severity: SeverityError,
+		File:     filepath.Join(metadataDirectory, fileName),
+		Message:  err.Error(),
+	}})
+}
+
+const (
+	checkMetadataLoad         = "metadata/load"
+	checkMetadataAnnotations  = "metadata/annotations"
+	checkMetadataProperties   = "metadata/properties"
+	checkMetadataDependencies = "metadata/dependencies"
+)
+
+func (m *Metadata) validate() error {
+	return runChecks(
+		check{checkMetadataAnnotations, m.validateAnnotations},
+		check{checkMetadataProperties, m.validateProperties},
+		check{checkMetadataDependencies, m.validateDependencies},
+	)
 }
 
 const (
@@ -191,7 +212,25 @@ const (
 	annotationPackage   = "operators.operatorframework.io.bundle.package.v1"
 )
 
-func (m *metadata) validateAnnotations() error {
+// NewAnnotations returns the annotations of a bundle of the package
+// packageName in the given channels. The default channel is optional.
+func NewAnnotations(packageName string, channels []string, defaultChannel string) Annotations {
+	annotations := map[string]string{
+		annotationMediaType: mediaType,
+		annotationManifests: manifestsDirectory,
+		annotationMetadata:  metadataDirectory,
+		annotationPackage:   packageName,
+	}
+	if len(channels) > 0 {
+		annotations[annotationChannels] = strings.Join(channels, ",")
+	}
+	if defaultChannel != "" {
+		annotations[annotationDefaultChannel] = defaultChannel
+	}
+	return Annotations{Annotations: annotations}
+}
+
+func (m *Metadata) validateAnnotations() error {
 	if err := func() error {
 		if len(m.annotationsFile.Value().Annotations) == 0 {
 			return errors.New("no annotations found")
@@ -248,7 +287,7 @@ func (m *metadata) validateAnnotations() error {
 	return nil
 }
 
-func (m *metadata) validateProperties() error {
+func (m *Metadata) validateProperties() error {
 	if m.propertiesFile == nil {
 		return nil
 	}
@@ -261,7 +300,7 @@ func (m *metadata) validateProperties() error {
 	return nil
 }
 
-func (m *metadata) validatePropertyTypeValues() error {
+func (m *Metadata) validatePropertyTypeValues() error {
 	var errs []error
 	for i, prop := range m.propertiesFile.Value().Properties {
 		validator := validatorFor(prop.Type, propertyScheme, true)
@@ -276,7 +315,7 @@ func (m *metadata) validatePropertyTypeValues() error {
 	return nil
 }
 
-func (m *metadata) validatePropertiesNoReservedUsage() error {
+func (m *Metadata) validatePropertiesNoReservedUsage() error {
 	reserved := sets.New[string](
 		typePropertyPackage,
 		typePropertyGVK,
@@ -294,7 +333,7 @@ func (m *metadata) validatePropertiesNoReservedUsage() error {
 	return nil
 }
 
-func (m *metadata) validateDependencies() error {
+func (m *Metadata) validateDependencies() error {
 	if m.dependenciesFile == nil {
 		return nil
 	}
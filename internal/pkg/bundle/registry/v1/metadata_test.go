@@ -14,7 +14,7 @@ func Test_metadataFSLoader_loadMetadata(t *testing.T) {
 	tests := []struct {
 		name      string
 		fsys      fs.FS
-		expected  *metadata
+		expected  *Metadata
 		assertErr require.ErrorAssertionFunc
 	}{
 		{
@@ -22,7 +22,7 @@ func Test_metadataFSLoader_loadMetadata(t *testing.T) {
 			fsys: fstest.MapFS{
 				annotationsFileName: &fstest.MapFile{Data: []byte(`annotations: {}`)},
 			},
-			expected: &metadata{
+			expected: &Metadata{
 				annotationsFile: NewPrecomputedFile[Annotations](annotationsFileName, []byte(`annotations: {}`), Annotations{Annotations: map[string]string{}}),
 			},
 			assertErr: require.NoError,
@@ -34,7 +34,7 @@ func Test_metadataFSLoader_loadMetadata(t *testing.T) {
 				propertiesFileName:   &fstest.MapFile{Data: []byte(`properties: [{"type":"a", "value":[]}]`)},
 				dependenciesFileName: &fstest.MapFile{Data: []byte(`dependencies: [{"type":"b", "value":{}}]`)},
 			},
-			expected: &metadata{
+			expected: &Metadata{
 				annotationsFile: NewPrecomputedFile[Annotations](annotationsFileName, []byte(`annotations: {"foo": "bar"}`),
 					Annotations{Annotations: map[string]string{"foo": "bar"}}),
 				propertiesFile: ptr.To(NewPrecomputedFile[Properties](propertiesFileName, []byte(`properties: [{"type":"a", "value":[]}]`),
@@ -71,12 +71,12 @@ func Test_metadataFSLoader_loadMetadata(t *testing.T) {
 func Test_Metadata_Validate(t *testing.T) {
 	tests := []struct {
 		name      string
-		metadata  metadata
+		metadata  Metadata
 		assertErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "passes all validations",
-			metadata: metadata{
+			metadata: Metadata{
 				annotationsFile: newAnnotationsFile(map[string]string{
 					annotationMediaType: mediaType,
 					annotationManifests: manifestsDirectory,
@@ -88,7 +88,7 @@ func Test_Metadata_Validate(t *testing.T) {
 		},
 		{
 			name: "validate collects suberrors",
-			metadata: metadata{
+			metadata: Metadata{
 				annotationsFile:  newAnnotationsFile(map[string]string{"foo": "bar"}),
 				propertiesFile:   newPropertiesFile([]Property{{Type: "a"}}),
 				dependenciesFile: newDependenciesFile([]Dependency{{Type: typeDependencyPackage}}),
@@ -192,7 +192,7 @@ func Test_Metadata_validateAnnotations(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &metadata{
+			m := &Metadata{
 				annotationsFile: newAnnotationsFile(tt.annotations),
 			}
 			err := m.validateAnnotations()
@@ -353,7 +353,7 @@ func Test_Metadata_validateProperties(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &metadata{
+			m := &Metadata{
 				propertiesFile: newPropertiesFile(tt.properties),
 			}
 			err := m.validateProperties()
@@ -503,7 +503,7 @@ func Test_Metadata_validateDependencies(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &metadata{
+			m := &Metadata{
 				dependenciesFile: newDependenciesFile(tt.dependencies),
 			}
 			err := m.validateDependencies()
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// MetadataOverrides change the metadata of a bundle. Nil annotations,
+// properties and dependencies leave the corresponding file unchanged unless
+// the file is replaced.
+type MetadataOverrides struct {
+	// Annotations are merged into metadata/annotations.yaml, overriding the
+	// values of existing keys, or replace its annotations.
+	Annotations        map[string]string
+	ReplaceAnnotations bool
+
+	// Properties are appended to metadata/properties.yaml, skipping
+	// properties that it already contains, or replace its properties.
+	// Replacing properties with none removes the file.
+	Properties        []Property
+	ReplaceProperties bool
+
+	// Dependencies are merged into or replace metadata/dependencies.yaml
+	// like properties.
+	Dependencies        []Dependency
+	ReplaceDependencies bool
+}
+
+// override returns a copy of m with overrides applied. Changed files are
+// regenerated; the result is not validated.
+func (m Metadata) override(overrides MetadataOverrides) (*Metadata, error) {
+	if overrides.Annotations != nil || overrides.ReplaceAnnotations {
+		annotations := maps.Clone(overrides.Annotations)
+		if !overrides.ReplaceAnnotations {
+			annotations = maps.Clone(m.annotationsFile.Value().Annotations)
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			maps.Copy(annotations, overrides.Annotations)
+		}
+		f, err := NewYAMLValueFile(annotationsFileName, Annotations{Annotations: annotations})
+		if err != nil {
+			return nil, err
+		}
+		m.annotationsFile = *f
+	}
+
+	if overrides.Properties != nil || overrides.ReplaceProperties {
+		var existing []Property
+		if m.propertiesFile != nil {
+			existing = m.propertiesFile.Value().Properties
+		}
+		properties := mergeProperties(existing, overrides.Properties, overrides.ReplaceProperties)
+		m.propertiesFile = nil
+		if len(properties) > 0 {
+			f, err := NewYAMLValueFile(propertiesFileName, Properties{Properties: properties})
+			if err != nil {
+				return nil, err
+			}
+			m.propertiesFile = f
+		}
+	}
+
+	if overrides.Dependencies != nil || overrides.ReplaceDependencies {
+		var existing []Dependency
+		if m.dependenciesFile != nil {
+			existing = m.dependenciesFile.Value().Dependencies
+		}
+		dependencies := mergeProperties(existing, overrides.Dependencies, overrides.ReplaceDependencies)
+		m.dependenciesFile = nil
+		if len(dependencies) > 0 {
+			f, err := NewYAMLValueFile(dependenciesFileName, Dependencies{Dependencies: dependencies})
+			if err != nil {
+				return nil, err
+			}
+			m.dependenciesFile = f
+		}
+	}
+	return &m, nil
+}
+
+// mergeProperties returns overrides if replace is set, and otherwise
+// existing followed by the overrides that it does not contain.
+func mergeProperties(existing, overrides []Property, replace bool) []Property {
+	if replace {
+		return slices.Clone(overrides)
+	}
+	merged := slices.Clone(existing)
+	for _, o := range overrides {
+		if !slices.ContainsFunc(merged, func(p Property) bool {
+			return p.Type == o.Type && equalJSON(p.Value, o.Value)
+		}) {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// equalJSON reports whether a and b encode the same JSON value, regardless
+// of formatting and escaping.
+func equalJSON(a, b json.RawMessage) bool {
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(av, bv)
+}
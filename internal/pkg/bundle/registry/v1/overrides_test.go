@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bundleFSLoader_MetadataOverrides(t *testing.T) {
+	requiredPackage := Property{Type: typePropertyPackageRequired, Value: json.RawMessage(`{"packageName":"other","versionRange":">=1.0.0"}`)}
+	customProperty := Property{Type: "example.com/custom", Value: json.RawMessage(`"value"`)}
+	packageDependency := Dependency{Type: typeDependencyPackage, Value: json.RawMessage(`{"packageName":"other","version":">=1.0.0"}`)}
+
+	tests := []struct {
+		name                 string
+		annotations          string
+		overrides            MetadataOverrides
+		expectedAnnotations  map[string]string
+		expectedProperties   *PropertiesFile
+		expectedDependencies *DependenciesFile
+		expectedErr          string
+	}{
+		{
+			name: "merge annotations",
+			overrides: MetadataOverrides{Annotations: map[string]string{
+				annotationChannels:       "stable,fast",
+				annotationDefaultChannel: "stable",
+			}},
+			expectedAnnotations: map[string]string{
+				annotationMediaType:      mediaType,
+				annotationManifests:      manifestsDirectory,
+				annotationMetadata:       metadataDirectory,
+				annotationPackage:        "example",
+				annotationChannels:       "stable,fast",
+				annotationDefaultChannel: "stable",
+			},
+		},
+		{
+			name: "supply missing package",
+			annotations: `
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+`,
+			overrides: MetadataOverrides{Annotations: map[string]string{annotationPackage: "example"}},
+			expectedAnnotations: map[string]string{
+				annotationMediaType: mediaType,
+				annotationManifests: manifestsDirectory,
+				annotationMetadata:  metadataDirectory,
+				annotationPackage:   "example",
+			},
+		},
+		{
+			name:        "replace annotations",
+			overrides:   MetadataOverrides{Annotations: map[string]string{annotationChannels: "stable"}, ReplaceAnnotations: true},
+			expectedErr: `required key "operators.operatorframework.io.bundle.package.v1" not found`,
+		},
+		{
+			name:               "merge properties",
+			overrides:          MetadataOverrides{Properties: []Property{requiredPackage, {Type: customProperty.Type, Value: json.RawMessage(` "value" `)}}},
+			expectedProperties: mustYAMLValueFile(t, propertiesFileName, Properties{Properties: []Property{customProperty, requiredPackage}}),
+		},
+		{
+			name:      "replace properties with none",
+			overrides: MetadataOverrides{ReplaceProperties: true},
+		},
+		{
+			name:        "reserved property",
+			overrides:   MetadataOverrides{Properties: []Property{{Type: typePropertyPackage, Value: json.RawMessage(`{}`)}}},
+			expectedErr: "reserved for use by OLM",
+		},
+		{
+			name:                 "merge dependencies",
+			overrides:            MetadataOverrides{Dependencies: []Dependency{packageDependency}},
+			expectedDependencies: mustYAMLValueFile(t, dependenciesFileName, Dependencies{Dependencies: []Dependency{packageDependency}}),
+		},
+		{
+			name:        "invalid dependency",
+			overrides:   MetadataOverrides{Dependencies: []Dependency{{Type: "unknown", Value: json.RawMessage(`{}`)}}},
+			expectedErr: `unknown type "unknown"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := validBundleFS()
+			fsys["metadata/properties.yaml"] = &fstest.MapFile{Data: []byte(`
+properties:
+  - type: example.com/custom
+    value: value
+`)}
+			original, err := NewBundleFSLoader(fsys).Load()
+			require.NoError(t, err)
+			if tt.annotations != "" {
+				fsys["metadata/annotations.yaml"] = &fstest.MapFile{Data: []byte(tt.annotations)}
+				_, err := NewBundleFSLoader(fsys).Load()
+				require.Error(t, err)
+			}
+
+			b, err := NewBundleFSLoaderWithOptions(fsys, LoadOptions{MetadataOverrides: &tt.overrides}).Load()
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.expectedAnnotations != nil {
+				expected := mustYAMLValueFile(t, annotationsFileName, Annotations{Annotations: tt.expectedAnnotations})
+				require.Equal(t, *expected, b.Metadata().Annotations())
+			} else {
+				require.Equal(t, original.Metadata().Annotations(), b.Metadata().Annotations())
+			}
+			if tt.overrides.Properties != nil || tt.overrides.ReplaceProperties {
+				require.Equal(t, tt.expectedProperties, b.Metadata().Properties())
+			}
+			require.Equal(t, tt.expectedDependencies, b.Metadata().Dependencies())
+
+			// The regenerated files are what gets marshaled.
+			reloaded, err := NewBundleFSLoader(b.toFS()).Load()
+			require.NoError(t, err)
+			for f := range b.Metadata().All() {
+				var found bool
+				for r := range reloaded.Metadata().All() {
+					if r.Name() == f.Name() {
+						require.Equal(t, f.Data(), r.Data())
+						found = true
+					}
+				}
+				require.True(t, found, "%s was not marshaled", f.Name())
+			}
+		})
+	}
+}
+
+func mustYAMLValueFile[T any](t *testing.T, name string, value T) *File[T] {
+	t.Helper()
+	f, err := NewYAMLValueFile(name, value)
+	require.NoError(t, err)
+	return f
+}
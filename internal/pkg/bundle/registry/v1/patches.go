@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	PatchTypeStrategicMerge = "StrategicMerge"
+	PatchTypeJSON6902       = "JSON6902"
+)
+
+// Patch is a strategic merge patch or JSON 6902 patch of the manifests that
+// match its target.
+type Patch struct {
+	Type   string
+	Target PatchTarget
+	// Patch is the patch document, in YAML or JSON.
+	Patch []byte
+}
+
+// PatchTarget selects manifests by the group, kind and name of their
+// object. Empty fields match any value.
+type PatchTarget struct {
+	Group string
+	Kind  string
+	Name  string
+}
+
+func (t PatchTarget) matches(obj client.Object) bool {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return (t.Group == "" || t.Group == gvk.Group) &&
+		(t.Kind == "" || t.Kind == gvk.Kind) &&
+		(t.Name == "" || t.Name == obj.GetName())
+}
+
+func (t PatchTarget) String() string {
+	return fmt.Sprintf("group=%q, kind=%q, name=%q", t.Group, t.Kind, t.Name)
+}
+
+// apply applies p to the JSON encoding of obj.
+func (p Patch) apply(data []byte, obj client.Object) ([]byte, error) {
+	patch, err := yaml.YAMLToJSON(p.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %v", err)
+	}
+	switch p.Type {
+	case PatchTypeStrategicMerge:
+		// Objects of kinds that kpm does not know have no patch strategies,
+		// so they are patched like kustomize patches them, with a JSON
+		// merge patch.
+		if _, ok := obj.(*unstructured.Unstructured); ok {
+			return jsonpatch.MergePatch(data, patch)
+		}
+		return strategicpatch.StrategicMergePatch(data, patch, obj)
+	case PatchTypeJSON6902:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: %v", err)
+		}
+		return ops.Apply(data)
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", p.Type)
+	}
+}
+
+// patch applies patches, in order, to the manifest files whose object
+// matches their targets, and returns the resulting files. A file is
+// re-serialized and its object decoded again after each patch, so that its
+// data matches its object and later patches match the object as patched
+// (e.g. by its new name); the result is not validated. Files that do not
+// contain exactly one object are left for validation to reject. Every patch
+// must match at least one manifest.
+func (m manifestFiles) patch(patches []Patch) (manifestFiles, error) {
+	for i, p := range patches {
+		if p.Type != PatchTypeStrategicMerge && p.Type != PatchTypeJSON6902 {
+			return nil, fmt.Errorf("patch %d: unknown patch type %q: must be %q or %q", i, p.Type, PatchTypeStrategicMerge, PatchTypeJSON6902)
+		}
+	}
+
+	matched := make([]bool, len(patches))
+	files := make(manifestFiles, 0, len(m))
+	for _, f := range m {
+		for i, p := range patches {
+			if len(f.Value()) != 1 || !p.Target.matches(f.Value()[0]) {
+				continue
+			}
+			matched[i] = true
+			patched, err := patchManifestFile(f, p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply patch %d to %s: %w", i, filepath.Join(manifestsDirectory, f.Name()), err)
+			}
+			f = *patched
+		}
+		files = append(files, f)
+	}
+	for i, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("patch %d matches no manifests (target %s)", i, patches[i].Target)
+		}
+	}
+	return files, nil
+}
+
+// patchManifestFile applies p to the single object of f and returns the
+// patched file.
+func patchManifestFile(f File[[]client.Object], p Patch) (*File[[]client.Object], error) {
+	data, err := yaml.YAMLToJSON(f.Data())
+	if err != nil {
+		return nil, err
+	}
+	if data, err = p.apply(data, f.Value()[0]); err != nil {
+		return nil, err
+	}
+	yamlData, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := newManifestFileFromReader(bytes.NewReader(yamlData), f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("patched manifest is invalid: %w", err)
+	}
+	return patched, nil
+}
@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_bundleFSLoader_Patches(t *testing.T) {
+	csvTarget := PatchTarget{Group: "operators.coreos.com", Kind: "ClusterServiceVersion"}
+	tests := []struct {
+		name string
+		// files replace files of the bundle before it is patched.
+		files       map[string]string
+		patches     []Patch
+		verify      func(*testing.T, *Bundle)
+		expectedErr string
+	}{
+		{
+			name: "strategic merge patches",
+			patches: []Patch{
+				{Type: PatchTypeStrategicMerge, Target: csvTarget, Patch: []byte(`
+spec:
+  description: Patched description.
+`)},
+				{Type: PatchTypeStrategicMerge, Target: PatchTarget{Kind: "Secret", Name: "example-secret"}, Patch: []byte(`
+metadata:
+  labels:
+    distribution: example
+`)},
+			},
+			verify: func(t *testing.T, b *Bundle) {
+				csv := b.Manifests().CSV().Value()
+				require.Equal(t, "Patched description.", csv.Spec.Description)
+				require.Equal(t, "1.2.3", csv.Spec.Version.String())
+				secret := b.Manifests().Others()[0].Value()
+				require.Equal(t, map[string]string{"distribution": "example"}, secret.GetLabels())
+			},
+		},
+		{
+			name: "JSON 6902 patch",
+			patches: []Patch{
+				{Type: PatchTypeJSON6902, Target: csvTarget, Patch: []byte(`
+- op: add
+  path: /spec/install/spec/deployments/0/spec/template/spec/containers/0/resources
+  value:
+    limits:
+      cpu: 500m
+`)},
+			},
+			verify: func(t *testing.T, b *Bundle) {
+				csv := b.Manifests().CSV().Value()
+				container := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Containers[0]
+				require.Equal(t, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}, container.Resources.Limits)
+				require.Equal(t, "quay.io/example/operator:v1.2.3", container.Image)
+			},
+		},
+		{
+			name: "patches apply in order",
+			patches: []Patch{
+				{Type: PatchTypeStrategicMerge, Target: csvTarget, Patch: []byte(`{"spec": {"description": "first"}}`)},
+				{Type: PatchTypeJSON6902, Target: csvTarget, Patch: []byte(`[{"op": "test", "path": "/spec/description", "value": "first"}, {"op": "replace", "path": "/spec/description", "value": "second"}]`)},
+			},
+			verify: func(t *testing.T, b *Bundle) {
+				require.Equal(t, "second", b.Manifests().CSV().Value().Spec.Description)
+			},
+		},
+		{
+			name: "repair invalid manifest",
+			files: map[string]string{"manifests/crd.yaml": `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: others.group.example.com
+spec:
+  names:
+    kind: Resource
+  versions:
+    - name: v1alpha1
+`},
+			patches: []Patch{{Type: PatchTypeJSON6902, Target: PatchTarget{Kind: "CustomResourceDefinition"}, Patch: []byte(`
+- op: replace
+  path: /metadata/name
+  value: resources.group.example.com
+`)}},
+			verify: func(t *testing.T, b *Bundle) {
+				require.Equal(t, "resources.group.example.com", b.Manifests().CRDs()[0].Value().GetName())
+			},
+		},
+		{
+			name: "targets match patched objects",
+			patches: []Patch{
+				{Type: PatchTypeJSON6902, Target: PatchTarget{Kind: "Secret", Name: "example-secret"}, Patch: []byte(`
+- op: replace
+  path: /metadata/name
+  value: renamed-secret
+`)},
+				{Type: PatchTypeStrategicMerge, Target: PatchTarget{Kind: "Secret", Name: "renamed-secret"}, Patch: []byte(`
+metadata:
+  labels:
+    distribution: example
+`)},
+			},
+			verify: func(t *testing.T, b *Bundle) {
+				secret := b.Manifests().Others()[0].Value()
+				require.Equal(t, "renamed-secret", secret.GetName())
+				require.Equal(t, map[string]string{"distribution": "example"}, secret.GetLabels())
+			},
+		},
+		{
+			name: "targets do not match original objects",
+			patches: []Patch{
+				{Type: PatchTypeJSON6902, Target: PatchTarget{Kind: "Secret", Name: "example-secret"}, Patch: []byte(`
+- op: replace
+  path: /metadata/name
+  value: renamed-secret
+`)},
+				{Type: PatchTypeStrategicMerge, Target: PatchTarget{Kind: "Secret", Name: "example-secret"}, Patch: []byte(`{}`)},
+			},
+			expectedErr: `patch 1 matches no manifests (target group="", kind="Secret", name="example-secret")`,
+		},
+		{
+			name:        "unmatched target",
+			patches:     []Patch{{Type: PatchTypeStrategicMerge, Target: PatchTarget{Kind: "Secret", Name: "missing"}, Patch: []byte(`{}`)}},
+			expectedErr: `patch 0 matches no manifests (target group="", kind="Secret", name="missing")`,
+		},
+		{
+			name:        "unknown type",
+			patches:     []Patch{{Type: "Merge", Target: csvTarget, Patch: []byte(`{}`)}},
+			expectedErr: `patch 0: unknown patch type "Merge"`,
+		},
+		{
+			name:        "failed patch",
+			patches:     []Patch{{Type: PatchTypeJSON6902, Target: csvTarget, Patch: []byte(`[{"op": "remove", "path": "/spec/missing"}]`)}},
+			expectedErr: "failed to apply patch 0 to manifests/csv.yaml",
+		},
+		{
+			name: "invalid result",
+			patches: []Patch{{Type: PatchTypeJSON6902, Target: PatchTarget{Kind: "CustomResourceDefinition"}, Patch: []byte(`
+- op: replace
+  path: /metadata/name
+  value: others.group.example.com
+`)}},
+			expectedErr: "resources.group.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := validBundleFS()
+			fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(testImagesCSV)}
+			for name, data := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(data)}
+			}
+
+			b, err := NewBundleFSLoaderWithOptions(fsys, LoadOptions{Patches: tt.patches}).Load()
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.verify(t, b)
+
+			// The patched files are what gets marshaled.
+			reloaded, err := NewBundleFSLoader(b.toFS()).Load()
+			require.NoError(t, err)
+			tt.verify(t, reloaded)
+			require.Equal(t, b.Manifests().CSV().Data(), reloaded.Manifests().CSV().Data())
+			require.IsType(t, &v1alpha1.ClusterServiceVersion{}, reloaded.Manifests().CSV().Value())
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package v1
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+const (
+	annotationTargetNamespaces = "olm.targetNamespaces"
+
+	defaultWebhookPort = 443
+)
+
+// clusterScopedKinds are the supported kinds that are not namespaced.
+var clusterScopedKinds = sets.New[string](
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"PriorityClass",
+	"ConsoleYAMLSample",
+	"ConsoleQuickStart",
+	"ConsoleCLIDownload",
+	"ConsoleLink",
+)
+
+// Render returns the objects that OLM would apply to install the bundle into
+// installNamespace, configured to watch watchNamespaces.
+//
+// If watchNamespaces is empty, the bundle is rendered for AllNamespaces if that
+// install mode is supported, and otherwise for OwnNamespace. A single empty
+// watch namespace also selects AllNamespaces. The install modes supported by
+// the CSV determine whether the requested watch namespaces are allowed.
+//
+// Webhook configurations and services are rendered without TLS certificates.
+// Those must be provisioned separately (e.g. with cert-manager) because OLM
+// normally generates them at install time.
+func (b *Bundle) Render(installNamespace string, watchNamespaces []string) ([]client.Object, error) {
+	if installNamespace == "" {
+		return nil, errors.New("install namespace is required")
+	}
+	csv := b.manifests.CSV().Value()
+	targetNamespaces, err := targetNamespacesFor(csv, installNamespace, watchNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	if len(csv.Spec.APIServiceDefinitions.Owned) > 0 {
+		return nil, errors.New("rendering bundles with owned apiServiceDefinitions is not supported")
+	}
+
+	r := renderer{
+		csv:              csv,
+		installNamespace: installNamespace,
+		targetNamespaces: targetNamespaces,
+	}
+
+	crds, err := r.crds(b.manifests.CRDs())
+	if err != nil {
+		return nil, err
+	}
+	webhookServices, webhookConfigs, err := r.webhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		others                 []client.Object
+		bundledServiceAccounts = sets.New[string]()
+	)
+	for _, other := range b.manifests.Others() {
+		obj := r.other(other.Value())
+		if obj.GetObjectKind().GroupVersionKind().Kind == "ServiceAccount" {
+			bundledServiceAccounts.Insert(obj.GetName())
+		}
+		others = append(others, obj)
+	}
+
+	var objs []client.Object
+	objs = append(objs, crds...)
+	objs = append(objs, r.serviceAccounts(bundledServiceAccounts)...)
+	objs = append(objs, r.rbac()...)
+	objs = append(objs, others...)
+	objs = append(objs, webhookServices...)
+	objs = append(objs, r.deployments()...)
+	objs = append(objs, webhookConfigs...)
+	return objs, nil
+}
+
+// targetNamespacesFor returns the namespaces the operator will watch, where
+// a single empty namespace means all namespaces.
+func targetNamespacesFor(csv *v1alpha1.ClusterServiceVersion, installNamespace string, watchNamespaces []string) ([]string, error) {
+	supported := sets.New[v1alpha1.InstallModeType]()
+	for _, installMode := range csv.Spec.InstallModes {
+		if installMode.Supported {
+			supported.Insert(installMode.Type)
+		}
+	}
+
+	watch := sets.New(watchNamespaces...)
+	if watch.Len() == 0 {
+		switch {
+		case supported.Has(v1alpha1.InstallModeTypeAllNamespaces):
+			watch.Insert(corev1.NamespaceAll)
+		case supported.Has(v1alpha1.InstallModeTypeOwnNamespace):
+			watch.Insert(installNamespace)
+		default:
+			return nil, errors.New("watch namespaces are required: bundle supports neither AllNamespaces nor OwnNamespace install mode")
+		}
+	}
+
+	requireInstallMode := func(installMode v1alpha1.InstallModeType) error {
+		if !supported.Has(installMode) {
+			return fmt.Errorf("watch namespaces %v require install mode %s, which is not supported by the bundle (supported: %v)", sets.List(watch), installMode, sets.List(supported))
+		}
+		return nil
+	}
+
+	switch {
+	case watch.Has(corev1.NamespaceAll):
+		if watch.Len() > 1 {
+			return nil, errors.New("watching all namespaces cannot be combined with other watch namespaces")
+		}
+		if err := requireInstallMode(v1alpha1.InstallModeTypeAllNamespaces); err != nil {
+			return nil, err
+		}
+	case watch.Len() > 1:
+		if err := requireInstallMode(v1alpha1.InstallModeTypeMultiNamespace); err != nil {
+			return nil, err
+		}
+	case watch.Has(installNamespace):
+		if err := requireInstallMode(v1alpha1.InstallModeTypeOwnNamespace); err != nil {
+			return nil, err
+		}
+	default:
+		if err := requireInstallMode(v1alpha1.InstallModeTypeSingleNamespace); err != nil {
+			return nil, err
+		}
+	}
+	return sets.List(watch), nil
+}
+
+type renderer struct {
+	csv              *v1alpha1.ClusterServiceVersion
+	installNamespace string
+	targetNamespaces []string
+}
+
+func (r *renderer) allNamespaces() bool {
+	return len(r.targetNamespaces) == 1 && r.targetNamespaces[0] == corev1.NamespaceAll
+}
+
+func objectMeta(name string, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: namespace}
+}
+
+func (r *renderer) deployments() []client.Object {
+	var objs []client.Object
+	for _, depSpec := range r.csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		dep := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: objectMeta(depSpec.Name, r.installNamespace),
+			Spec:       *depSpec.Spec.DeepCopy(),
+		}
+		if len(depSpec.Label) > 0 {
+			dep.Labels = maps.Clone(depSpec.Label)
+		}
+
+		podAnnotations := maps.Clone(r.csv.Annotations)
+		if podAnnotations == nil {
+			podAnnotations = map[string]string{}
+		}
+		maps.Copy(podAnnotations, dep.Spec.Template.Annotations)
+		podAnnotations[annotationTargetNamespaces] = strings.Join(r.targetNamespaces, ",")
+		dep.Spec.Template.Annotations = podAnnotations
+
+		objs = append(objs, dep)
+	}
+	return objs
+}
+
+func (r *renderer) serviceAccountNames() []string {
+	strategy := r.csv.Spec.InstallStrategy.StrategySpec
+	names := sets.New[string]()
+	for _, perms := range slices.Concat(strategy.Permissions, strategy.ClusterPermissions) {
+		names.Insert(perms.ServiceAccountName)
+	}
+	for _, depSpec := range strategy.DeploymentSpecs {
+		names.Insert(depSpec.Spec.Template.Spec.ServiceAccountName)
+	}
+	names.Delete("", "default")
+	return sets.List(names)
+}
+
+// serviceAccounts returns the service accounts used by the install strategy,
+// except for those that are already included in the bundle.
+func (r *renderer) serviceAccounts(bundled sets.Set[string]) []client.Object {
+	var objs []client.Object
+	for _, name := range r.serviceAccountNames() {
+		if bundled.Has(name) {
+			continue
+		}
+		objs = append(objs, &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ServiceAccount"},
+			ObjectMeta: objectMeta(name, r.installNamespace),
+		})
+	}
+	return objs
+}
+
+func (r *renderer) rbac() []client.Object {
+	var (
+		clusterRoles, clusterRoleBindings []client.Object
+		roles, roleBindings               []client.Object
+	)
+
+	addClusterRole := func(name string, perms v1alpha1.StrategyDeploymentPermissions) {
+		clusterRoles = append(clusterRoles, &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: objectMeta(name, ""),
+			Rules:      slices.Clone(perms.Rules),
+		})
+		clusterRoleBindings = append(clusterRoleBindings, &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: objectMeta(name, ""),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+			Subjects:   r.subjects(perms.ServiceAccountName),
+		})
+	}
+
+	strategy := r.csv.Spec.InstallStrategy.StrategySpec
+	for _, perms := range strategy.Permissions {
+		name := r.rbacName(perms.ServiceAccountName, "")
+		if r.allNamespaces() {
+			addClusterRole(name, perms)
+			continue
+		}
+		for _, ns := range r.targetNamespaces {
+			roles = append(roles, &rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: objectMeta(name, ns),
+				Rules:      slices.Clone(perms.Rules),
+			})
+			roleBindings = append(roleBindings, &rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: objectMeta(name, ns),
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+				Subjects:   r.subjects(perms.ServiceAccountName),
+			})
+		}
+	}
+	for _, perms := range strategy.ClusterPermissions {
+		addClusterRole(r.rbacName(perms.ServiceAccountName, "cluster"), perms)
+	}
+	return slices.Concat(clusterRoles, clusterRoleBindings, roles, roleBindings)
+}
+
+func (r *renderer) rbacName(serviceAccountName string, suffix string) string {
+	name := fmt.Sprintf("%s-%s", r.csv.Name, cmp.Or(serviceAccountName, "default"))
+	if suffix != "" {
+		name = fmt.Sprintf("%s-%s", name, suffix)
+	}
+	return name
+}
+
+func (r *renderer) subjects(serviceAccountName string) []rbacv1.Subject {
+	return []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      cmp.Or(serviceAccountName, "default"),
+		Namespace: r.installNamespace,
+	}}
+}
+
+func (r *renderer) other(obj client.Object) client.Object {
+	obj = obj.DeepCopyObject().(client.Object)
+	if !clusterScopedKinds.Has(obj.GetObjectKind().GroupVersionKind().Kind) {
+		obj.SetNamespace(r.installNamespace)
+	}
+	return obj
+}
+
+func webhookServiceName(deploymentName string) string {
+	return fmt.Sprintf("%s-service", deploymentName)
+}
+
+func webhookServiceReference(wh v1alpha1.WebhookDescription) (string, *int32) {
+	return webhookServiceName(wh.DeploymentName), ptr.To(cmp.Or(wh.ContainerPort, defaultWebhookPort))
+}
+
+func (r *renderer) webhooks() ([]client.Object, []client.Object, error) {
+	deployments := map[string]v1alpha1.StrategyDeploymentSpec{}
+	for _, depSpec := range r.csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		deployments[depSpec.Name] = depSpec
+	}
+
+	var (
+		errs         []error
+		servicePorts = map[string][]corev1.ServicePort{}
+		configs      []client.Object
+	)
+	for _, wh := range r.csv.Spec.WebhookDefinitions {
+		if _, ok := deployments[wh.DeploymentName]; !ok {
+			errs = append(errs, fmt.Errorf("webhook %q references unknown deployment %q", wh.GenerateName, wh.DeploymentName))
+			continue
+		}
+
+		port := cmp.Or(wh.ContainerPort, defaultWebhookPort)
+		targetPort := intstr.FromInt32(port)
+		if wh.TargetPort != nil {
+			targetPort = *wh.TargetPort
+		}
+		servicePort := corev1.ServicePort{
+			Name:       fmt.Sprintf("%d", port),
+			Port:       port,
+			TargetPort: targetPort,
+		}
+		if !slices.Contains(servicePorts[wh.DeploymentName], servicePort) {
+			servicePorts[wh.DeploymentName] = append(servicePorts[wh.DeploymentName], servicePort)
+		}
+
+		serviceName, servicePort32 := webhookServiceReference(wh)
+		clientConfig := admissionregistrationv1.WebhookClientConfig{
+			Service: &admissionregistrationv1.ServiceReference{
+				Namespace: r.installNamespace,
+				Name:      serviceName,
+				Path:      wh.WebhookPath,
+				Port:      servicePort32,
+			},
+		}
+
+		switch wh.Type {
+		case v1alpha1.ValidatingAdmissionWebhook:
+			configs = append(configs, &admissionregistrationv1.ValidatingWebhookConfiguration{
+				TypeMeta:   metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"},
+				ObjectMeta: objectMeta(wh.GenerateName, ""),
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+					Name:                    wh.GenerateName,
+					ClientConfig:            clientConfig,
+					Rules:                   wh.Rules,
+					FailurePolicy:           wh.FailurePolicy,
+					MatchPolicy:             wh.MatchPolicy,
+					NamespaceSelector:       r.webhookNamespaceSelector(),
+					ObjectSelector:          wh.ObjectSelector,
+					SideEffects:             wh.SideEffects,
+					TimeoutSeconds:          wh.TimeoutSeconds,
+					AdmissionReviewVersions: wh.AdmissionReviewVersions,
+				}},
+			})
+		case v1alpha1.MutatingAdmissionWebhook:
+			configs = append(configs, &admissionregistrationv1.MutatingWebhookConfiguration{
+				TypeMeta:   metav1.TypeMeta{APIVersion: admissionregistrationv1.SchemeGroupVersion.String(), Kind: "MutatingWebhookConfiguration"},
+				ObjectMeta: objectMeta(wh.GenerateName, ""),
+				Webhooks: []admissionregistrationv1.MutatingWebhook{{
+					Name:                    wh.GenerateName,
+					ClientConfig:            clientConfig,
+					Rules:                   wh.Rules,
+					FailurePolicy:           wh.FailurePolicy,
+					MatchPolicy:             wh.MatchPolicy,
+					NamespaceSelector:       r.webhookNamespaceSelector(),
+					ObjectSelector:          wh.ObjectSelector,
+					SideEffects:             wh.SideEffects,
+					TimeoutSeconds:          wh.TimeoutSeconds,
+					AdmissionReviewVersions: wh.AdmissionReviewVersions,
+					ReinvocationPolicy:      wh.ReinvocationPolicy,
+				}},
+			})
+		case v1alpha1.ConversionWebhook:
+			// Conversion webhooks are rendered into the CRDs they convert.
+		default:
+			errs = append(errs, fmt.Errorf("webhook %q has unknown type %q", wh.GenerateName, wh.Type))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, fmt.Errorf("invalid webhook definitions: %v", err)
+	}
+
+	var services []client.Object
+	for _, deploymentName := range slices.Sorted(maps.Keys(servicePorts)) {
+		var selector map[string]string
+		if s := deployments[deploymentName].Spec.Selector; s != nil {
+			selector = maps.Clone(s.MatchLabels)
+		}
+		services = append(services, &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Service"},
+			ObjectMeta: objectMeta(webhookServiceName(deploymentName), r.installNamespace),
+			Spec: corev1.ServiceSpec{
+				Selector: selector,
+				Ports:    servicePorts[deploymentName],
+			},
+		})
+	}
+	return services, configs, nil
+}
+
+func (r *renderer) webhookNamespaceSelector() *metav1.LabelSelector {
+	if r.allNamespaces() {
+		return nil
+	}
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      corev1.LabelMetadataName,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   slices.Clone(r.targetNamespaces),
+		}},
+	}
+}
+
+func (r *renderer) crds(crdFiles []File[*apiextensionsv1.CustomResourceDefinition]) ([]client.Object, error) {
+	conversions := map[string]v1alpha1.WebhookDescription{}
+	var errs []error
+	for _, wh := range r.csv.Spec.WebhookDefinitions {
+		if wh.Type != v1alpha1.ConversionWebhook {
+			continue
+		}
+		if !r.allNamespaces() {
+			errs = append(errs, fmt.Errorf("conversion webhook %q requires the AllNamespaces install mode", wh.GenerateName))
+			continue
+		}
+		for _, crdName := range wh.ConversionCRDs {
+			if _, ok := conversions[crdName]; ok {
+				errs = append(errs, fmt.Errorf("CRD %q is referenced by multiple conversion webhooks", crdName))
+				continue
+			}
+			conversions[crdName] = wh
+		}
+	}
+
+	var objs []client.Object
+	for _, crdFile := range crdFiles {
+		crd := crdFile.Value().DeepCopy()
+		if wh, ok := conversions[crd.Name]; ok {
+			serviceName, port := webhookServiceReference(wh)
+			crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{
+							Namespace: r.installNamespace,
+							Name:      serviceName,
+							Path:      wh.WebhookPath,
+							Port:      port,
+						},
+					},
+					ConversionReviewVersions: wh.AdmissionReviewVersions,
+				},
+			}
+			delete(conversions, crd.Name)
+		}
+		objs = append(objs, crd)
+	}
+	for _, crdName := range slices.Sorted(maps.Keys(conversions)) {
+		errs = append(errs, fmt.Errorf("conversion webhook %q references CRD %q, which is not in the bundle", conversions[crdName].GenerateName, crdName))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid webhook definitions: %v", err)
+	}
+	return objs, nil
+}
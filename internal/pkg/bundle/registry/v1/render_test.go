@@ -0,0 +1,294 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const renderTestCSV = `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  installModes:
+    - type: OwnNamespace
+      supported: true
+    - type: SingleNamespace
+      supported: true
+    - type: MultiNamespace
+      supported: true
+    - type: AllNamespaces
+      supported: true
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+        - name: example-operator
+          spec:
+            selector:
+              matchLabels:
+                app: example-operator
+            template:
+              metadata:
+                labels:
+                  app: example-operator
+              spec:
+                serviceAccountName: example-operator
+                containers:
+                  - name: manager
+                    image: quay.io/example/operator:v1.2.3
+      permissions:
+        - serviceAccountName: example-operator
+          rules:
+            - apiGroups: [""]
+              resources: ["configmaps"]
+              verbs: ["get"]
+      clusterPermissions:
+        - serviceAccountName: example-operator
+          rules:
+            - apiGroups: ["group.example.com"]
+              resources: ["resources"]
+              verbs: ["*"]
+  webhookdefinitions:
+    - generateName: vresource.group.example.com
+      type: ValidatingAdmissionWebhook
+      deploymentName: example-operator
+      containerPort: 9443
+      webhookPath: /validate
+      sideEffects: None
+      admissionReviewVersions: ["v1"]
+`
+
+func renderTestBundle(t *testing.T, csv string) *Bundle {
+	t.Helper()
+	fsys := validBundleFS()
+	fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(csv)}
+	b, err := NewBundleFSLoader(fsys).Load()
+	require.NoError(t, err)
+	return b
+}
+
+func Test_Bundle_Render_InstallModes(t *testing.T) {
+	type namespacedName struct {
+		kind, namespace, name string
+	}
+	tests := []struct {
+		name              string
+		watchNamespaces   []string
+		expectedRBAC      []namespacedName
+		expectedTargetNSs string
+		expectedSelector  []string
+	}{
+		{
+			name:            "AllNamespaces (default)",
+			watchNamespaces: nil,
+			expectedRBAC: []namespacedName{
+				{"ClusterRole", "", "example.v1.2.3-example-operator"},
+				{"ClusterRole", "", "example.v1.2.3-example-operator-cluster"},
+				{"ClusterRoleBinding", "", "example.v1.2.3-example-operator"},
+				{"ClusterRoleBinding", "", "example.v1.2.3-example-operator-cluster"},
+			},
+			expectedTargetNSs: "",
+		},
+		{
+			name:            "OwnNamespace",
+			watchNamespaces: []string{"install-ns"},
+			expectedRBAC: []namespacedName{
+				{"ClusterRole", "", "example.v1.2.3-example-operator-cluster"},
+				{"ClusterRoleBinding", "", "example.v1.2.3-example-operator-cluster"},
+				{"Role", "install-ns", "example.v1.2.3-example-operator"},
+				{"RoleBinding", "install-ns", "example.v1.2.3-example-operator"},
+			},
+			expectedTargetNSs: "install-ns",
+			expectedSelector:  []string{"install-ns"},
+		},
+		{
+			name:            "SingleNamespace",
+			watchNamespaces: []string{"watch-ns"},
+			expectedRBAC: []namespacedName{
+				{"ClusterRole", "", "example.v1.2.3-example-operator-cluster"},
+				{"ClusterRoleBinding", "", "example.v1.2.3-example-operator-cluster"},
+				{"Role", "watch-ns", "example.v1.2.3-example-operator"},
+				{"RoleBinding", "watch-ns", "example.v1.2.3-example-operator"},
+			},
+			expectedTargetNSs: "watch-ns",
+			expectedSelector:  []string{"watch-ns"},
+		},
+		{
+			name:            "MultiNamespace",
+			watchNamespaces: []string{"watch-b", "watch-a"},
+			expectedRBAC: []namespacedName{
+				{"ClusterRole", "", "example.v1.2.3-example-operator-cluster"},
+				{"ClusterRoleBinding", "", "example.v1.2.3-example-operator-cluster"},
+				{"Role", "watch-a", "example.v1.2.3-example-operator"},
+				{"Role", "watch-b", "example.v1.2.3-example-operator"},
+				{"RoleBinding", "watch-a", "example.v1.2.3-example-operator"},
+				{"RoleBinding", "watch-b", "example.v1.2.3-example-operator"},
+			},
+			expectedTargetNSs: "watch-a,watch-b",
+			expectedSelector:  []string{"watch-a", "watch-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := renderTestBundle(t, renderTestCSV)
+			objs, err := b.Render("install-ns", tt.watchNamespaces)
+			require.NoError(t, err)
+
+			var (
+				rbac       []namespacedName
+				deployment *appsv1.Deployment
+				webhook    *admissionregistrationv1.ValidatingWebhookConfiguration
+				service    *corev1.Service
+			)
+			for _, obj := range objs {
+				switch o := obj.(type) {
+				case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding, *rbacv1.Role, *rbacv1.RoleBinding:
+					rbac = append(rbac, namespacedName{obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName()})
+				case *appsv1.Deployment:
+					deployment = o
+				case *admissionregistrationv1.ValidatingWebhookConfiguration:
+					webhook = o
+				case *corev1.Service:
+					service = o
+				}
+			}
+			require.Equal(t, tt.expectedRBAC, rbac)
+
+			require.NotNil(t, deployment)
+			require.Equal(t, "install-ns", deployment.Namespace)
+			require.Equal(t, tt.expectedTargetNSs, deployment.Spec.Template.Annotations["olm.targetNamespaces"])
+
+			require.NotNil(t, service)
+			require.Equal(t, "example-operator-service", service.Name)
+			require.Equal(t, map[string]string{"app": "example-operator"}, service.Spec.Selector)
+			require.Equal(t, int32(9443), service.Spec.Ports[0].Port)
+
+			require.NotNil(t, webhook)
+			require.Equal(t, "example-operator-service", webhook.Webhooks[0].ClientConfig.Service.Name)
+			require.Equal(t, "install-ns", webhook.Webhooks[0].ClientConfig.Service.Namespace)
+			if tt.expectedSelector == nil {
+				require.Nil(t, webhook.Webhooks[0].NamespaceSelector)
+			} else {
+				require.Equal(t, tt.expectedSelector, webhook.Webhooks[0].NamespaceSelector.MatchExpressions[0].Values)
+			}
+		})
+	}
+}
+
+func Test_Bundle_Render_UnsupportedInstallModes(t *testing.T) {
+	csv := `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  installModes:
+    - type: OwnNamespace
+      supported: true
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+`
+	tests := []struct {
+		name            string
+		watchNamespaces []string
+		assertErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:      "defaults to OwnNamespace",
+			assertErr: require.NoError,
+		},
+		{
+			name:            "AllNamespaces not supported",
+			watchNamespaces: []string{""},
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "require install mode AllNamespaces")
+			},
+		},
+		{
+			name:            "SingleNamespace not supported",
+			watchNamespaces: []string{"other"},
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "require install mode SingleNamespace")
+			},
+		},
+		{
+			name:            "MultiNamespace not supported",
+			watchNamespaces: []string{"install-ns", "other"},
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "require install mode MultiNamespace")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := renderTestBundle(t, csv)
+			_, err := b.Render("install-ns", tt.watchNamespaces)
+			tt.assertErr(t, err)
+		})
+	}
+}
+
+func Test_Bundle_Render_ConversionWebhook(t *testing.T) {
+	csv := renderTestCSV + `
+    - generateName: cresource.group.example.com
+      type: ConversionWebhook
+      deploymentName: example-operator
+      webhookPath: /convert
+      sideEffects: None
+      admissionReviewVersions: ["v1"]
+      conversionCRDs: ["resources.group.example.com"]
+`
+	b := renderTestBundle(t, csv)
+
+	objs, err := b.Render("install-ns", nil)
+	require.NoError(t, err)
+	var crd *apiextensionsv1.CustomResourceDefinition
+	for _, obj := range objs {
+		if o, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+			crd = o
+		}
+	}
+	require.NotNil(t, crd)
+	require.Equal(t, apiextensionsv1.WebhookConverter, crd.Spec.Conversion.Strategy)
+	require.Equal(t, "example-operator-service", crd.Spec.Conversion.Webhook.ClientConfig.Service.Name)
+	require.Nil(t, b.Manifests().CRDs()[0].Value().Spec.Conversion, "rendering must not modify the bundle")
+
+	_, err = b.Render("install-ns", []string{"install-ns"})
+	require.ErrorContains(t, err, "requires the AllNamespaces install mode")
+}
+
+func Test_Bundle_Render_NamespacesOthers(t *testing.T) {
+	b := renderTestBundle(t, renderTestCSV)
+	objs, err := b.Render("install-ns", nil)
+	require.NoError(t, err)
+
+	var secret client.Object
+	for _, obj := range objs {
+		if _, ok := obj.(*corev1.Secret); ok {
+			secret = obj
+		}
+	}
+	require.NotNil(t, secret)
+	require.Equal(t, "install-ns", secret.GetNamespace())
+	require.Empty(t, b.Manifests().Others()[0].Value().GetNamespace(), "rendering must not modify the bundle")
+}
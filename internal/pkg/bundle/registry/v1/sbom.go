@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"cmp"
+	"io/fs"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
+)
+
+// Inventory returns the content of the bundle to describe in its SBOM: every
+// file in the bundle, its CRDs, and the images referenced by its CSV
+// deployments and related images.
+func (b *Bundle) Inventory() (sbom.Inventory, error) {
+	images := csvImages(b.manifests.CSV().Value())
+	images.Delete("")
+	inv := sbom.Inventory{
+		Name:    b.metadata.PackageName(),
+		Version: b.tag(),
+		Images:  sets.List(images),
+	}
+
+	bundleFS := b.toFS()
+	if err := fs.WalkDir(bundleFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(bundleFS, path)
+		if err != nil {
+			return err
+		}
+		inv.Files = append(inv.Files, sbom.File{Path: path, Digest: digest.FromBytes(data)})
+		return nil
+	}); err != nil {
+		return sbom.Inventory{}, err
+	}
+
+	for _, crd := range b.manifests.CRDs() {
+		c := crd.Value()
+		sbomCRD := sbom.CRD{Name: c.Name, Group: c.Spec.Group}
+		for _, v := range c.Spec.Versions {
+			sbomCRD.Versions = append(sbomCRD.Versions, v.Name)
+		}
+		inv.CRDs = append(inv.CRDs, sbomCRD)
+	}
+	slices.SortFunc(inv.CRDs, func(a, b sbom.CRD) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return inv, nil
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
+)
+
+func Test_Bundle_Inventory(t *testing.T) {
+	fsys := validBundleFS()
+	fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  relatedImages:
+    - name: operator
+      image: quay.io/example/operator:v1.2.3
+    - name: operand
+      image: quay.io/example/operand@sha256:1111111111111111111111111111111111111111111111111111111111111111
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+        - name: operator
+          spec:
+            template:
+              spec:
+                initContainers:
+                  - name: init
+                    image: quay.io/example/init:v1
+                containers:
+                  - name: operator
+                    image: quay.io/example/operator:v1.2.3
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+      - name: resources.group.example.com
+        version: v1
+        kind: Resource
+`)}
+	fsys["manifests/crd.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resources.group.example.com
+spec:
+  group: group.example.com
+  names:
+    kind: Resource
+  versions:
+    - name: v1alpha1
+    - name: v1
+`)}
+
+	b, err := NewBundleFSLoader(fsys).Load()
+	require.NoError(t, err)
+	inv, err := b.Inventory()
+	require.NoError(t, err)
+
+	require.Equal(t, "example", inv.Name)
+	require.Equal(t, "1.2.3", inv.Version)
+	require.Equal(t, []sbom.CRD{
+		{Name: "resources.group.example.com", Group: "group.example.com", Versions: []string{"v1alpha1", "v1"}},
+	}, inv.CRDs)
+	require.Equal(t, []string{
+		"quay.io/example/init:v1",
+		"quay.io/example/operand@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"quay.io/example/operator:v1.2.3",
+	}, inv.Images)
+
+	var paths []string
+	for _, f := range inv.Files {
+		paths = append(paths, f.Path)
+		require.Equal(t, digest.FromBytes(fsys[f.Path].Data), f.Digest)
+	}
+	require.Equal(t, []string{
+		"manifests/crd.yaml",
+		"manifests/csv.yaml",
+		"manifests/secret.yaml",
+		"metadata/annotations.yaml",
+	}, paths)
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+const (
+	annotationChannels       = "operators.operatorframework.io.bundle.channels.v1"
+	annotationDefaultChannel = "operators.operatorframework.io.bundle.channel.default.v1"
+)
+
+// Summary is a human-oriented overview of the contents of a bundle.
+type Summary struct {
+	ID             string                     `json:"id"`
+	Package        string                     `json:"package"`
+	Version        string                     `json:"version"`
+	Channels       []string                   `json:"channels,omitempty"`
+	DefaultChannel string                     `json:"defaultChannel,omitempty"`
+	InstallModes   []v1alpha1.InstallModeType `json:"installModes,omitempty"`
+	OwnedCRDs      []CRDReference             `json:"ownedCRDs,omitempty"`
+	RequiredCRDs   []CRDReference             `json:"requiredCRDs,omitempty"`
+	RelatedImages  []v1alpha1.RelatedImage    `json:"relatedImages,omitempty"`
+	Manifests      []ManifestReference        `json:"manifests"`
+}
+
+type ManifestReference struct {
+	File string `json:"file"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type CRDReference struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+func (r CRDReference) String() string {
+	return fmt.Sprintf("%s/%s (%s)", r.Name, r.Version, r.Kind)
+}
+
+func toCRDReferences(descs []v1alpha1.CRDDescription) []CRDReference {
+	var refs []CRDReference
+	for _, desc := range descs {
+		refs = append(refs, CRDReference{Name: desc.Name, Version: desc.Version, Kind: desc.Kind})
+	}
+	return refs
+}
+
+func (b *Bundle) Summary() Summary {
+	csv := b.manifests.CSV().Value()
+	annotations := b.metadata.Annotations().Value().Annotations
+
+	var channels []string
+	for _, ch := range strings.Split(annotations[annotationChannels], ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+
+	var installModes []v1alpha1.InstallModeType
+	for _, installMode := range csv.Spec.InstallModes {
+		if installMode.Supported {
+			installModes = append(installModes, installMode.Type)
+		}
+	}
+
+	var manifests []ManifestReference
+	for f := range b.manifests.All() {
+		manifests = append(manifests, ManifestReference{
+			File: f.Name(),
+			Kind: f.Value().GetObjectKind().GroupVersionKind().Kind,
+			Name: f.Value().GetName(),
+		})
+	}
+
+	return Summary{
+		ID:             b.ID(),
+		Package:        b.metadata.PackageName(),
+		Version:        b.tag(),
+		Channels:       channels,
+		DefaultChannel: annotations[annotationDefaultChannel],
+		InstallModes:   installModes,
+		OwnedCRDs:      toCRDReferences(csv.Spec.CustomResourceDefinitions.Owned),
+		RequiredCRDs:   toCRDReferences(csv.Spec.CustomResourceDefinitions.Required),
+		RelatedImages:  csv.Spec.RelatedImages,
+		Manifests:      manifests,
+	}
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func Test_Bundle_Summary(t *testing.T) {
+	fsys := validBundleFS()
+	fsys["manifests/csv.yaml"] = &fstest.MapFile{Data: []byte(`
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+  installModes:
+    - type: OwnNamespace
+      supported: true
+    - type: AllNamespaces
+      supported: false
+  relatedImages:
+    - name: operator
+      image: quay.io/example/operator:v1.2.3
+  customresourcedefinitions:
+    owned:
+      - name: resources.group.example.com
+        version: v1alpha1
+        kind: Resource
+    required:
+      - name: others.group.example.com
+        version: v1
+        kind: Other
+`)}
+	fsys["metadata/annotations.yaml"] = &fstest.MapFile{Data: []byte(`
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: example
+  operators.operatorframework.io.bundle.channels.v1: fast, stable
+  operators.operatorframework.io.bundle.channel.default.v1: stable
+`)}
+
+	b, err := NewBundleFSLoader(fsys).Load()
+	require.NoError(t, err)
+	require.Equal(t, Summary{
+		ID:             "example.v1.2.3",
+		Package:        "example",
+		Version:        "1.2.3",
+		Channels:       []string{"fast", "stable"},
+		DefaultChannel: "stable",
+		InstallModes:   []v1alpha1.InstallModeType{v1alpha1.InstallModeTypeOwnNamespace},
+		OwnedCRDs:      []CRDReference{{Name: "resources.group.example.com", Version: "v1alpha1", Kind: "Resource"}},
+		RequiredCRDs:   []CRDReference{{Name: "others.group.example.com", Version: "v1", Kind: "Other"}},
+		RelatedImages:  []v1alpha1.RelatedImage{{Name: "operator", Image: "quay.io/example/operator:v1.2.3"}},
+		Manifests: []ManifestReference{
+			{File: "csv.yaml", Kind: "ClusterServiceVersion", Name: "example.v1.2.3"},
+			{File: "crd.yaml", Kind: "CustomResourceDefinition", Name: "resources.group.example.com"},
+			{File: "secret.yaml", Kind: "Secret", Name: "example-secret"},
+		},
+	}, b.Summary())
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity string
+
+const (
+	// SeverityError diagnostics make a bundle invalid.
+	SeverityError Severity = "error"
+	// SeverityWarning diagnostics report problems that do not prevent a
+	// bundle from being built.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic describes a single failed bundle validation check.
+type Diagnostic struct {
+	// Check is the name of the failed check, e.g. "manifests/exactly-one-csv".
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	// File is the path of the file that caused the failure, relative to
+	// the bundle root, if the failure can be attributed to a single file.
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	prefix := fmt.Sprintf("[%s]", d.Check)
+	if d.Severity == SeverityWarning {
+		prefix += " warning:"
+	}
+	if d.File != "" {
+		return fmt.Sprintf("%s %s: %s", prefix, d.File, d.Message)
+	}
+	return fmt.Sprintf("%s %s", prefix, d.Message)
+}
+
+// ValidationError is returned by bundle loaders when a bundle is invalid. It
+// contains a diagnostic for every failed check.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+// HasErrors reports whether any of the diagnostics of e is an error, rather
+// than a warning.
+func (e *ValidationError) HasErrors() bool {
+	for _, d := range e.Diagnostics {
+		if d.Severity != SeverityWarning {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		msgs = append(msgs, d.String())
+	}
+	return fmt.Sprintf("invalid registry+v1 bundle: %s", strings.Join(msgs, "\n"))
+}
+
+func newValidationError(diagnostics []Diagnostic) error {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	return &ValidationError{Diagnostics: diagnostics}
+}
+
+// joinValidationErrors combines errs. If every non-nil error is a
+// ValidationError, the result is a single ValidationError containing all of
+// their diagnostics. Otherwise, the errors are joined.
+func joinValidationErrors(errs ...error) error {
+	var diagnostics []Diagnostic
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			return errors.Join(errs...)
+		}
+		diagnostics = append(diagnostics, validationErr.Diagnostics...)
+	}
+	return newValidationError(diagnostics)
+}
+
+type check struct {
+	name string
+	fn   func() error
+}
+
+// runChecks runs every check and returns a ValidationError containing a
+// diagnostic for each check that failed.
+func runChecks(checks ...check) error {
+	var diagnostics []Diagnostic
+	for _, c := range checks {
+		if err := c.fn(); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Check: c.name, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	return newValidationError(diagnostics)
+}
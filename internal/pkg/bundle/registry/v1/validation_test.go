@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BundleFSLoader_ValidationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(fstest.MapFS)
+		expected []Diagnostic
+	}{
+		{
+			name: "unparseable manifest and missing annotations",
+			mutate: func(fsys fstest.MapFS) {
+				fsys["manifests/broken.yaml"] = &fstest.MapFile{Data: []byte("}")}
+				delete(fsys, "metadata/annotations.yaml")
+			},
+			expected: []Diagnostic{
+				{Check: checkManifestsLoad, File: "manifests/broken.yaml"},
+				{Check: checkMetadataLoad, File: "metadata/annotations.yaml"},
+			},
+		},
+		{
+			name: "no csv reports every failed check",
+			mutate: func(fsys fstest.MapFS) {
+				delete(fsys, "manifests/csv.yaml")
+			},
+			expected: []Diagnostic{
+				{Check: checkManifestsExactlyOneCSV},
+				{Check: checkManifestsOwnedAPIs},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := validBundleFS()
+			tt.mutate(fsys)
+
+			_, err := NewBundleFSLoader(fsys).Load()
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr), "expected ValidationError, got %v", err)
+
+			actual := make([]Diagnostic, 0, len(validationErr.Diagnostics))
+			for _, d := range validationErr.Diagnostics {
+				actual = append(actual, Diagnostic{Check: d.Check, File: d.File})
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
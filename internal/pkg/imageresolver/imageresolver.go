@@ -0,0 +1,56 @@
+// Package imageresolver resolves container image references to digests, so
+// that packages can reference images by digest rather than by tag. Images can
+// be resolved online against their registries, or offline using a local OCI
+// image layout or a mapping file.
+package imageresolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+)
+
+// Resolver resolves an image reference to the digest of its manifest.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (digest.Digest, error)
+}
+
+// PinnedImage is an image reference that was pinned to a digest.
+type PinnedImage struct {
+	Original string `json:"original"`
+	Pinned   string `json:"pinned"`
+}
+
+// Pin returns the reference ref pinned to the digest that resolver resolves
+// it to, e.g. quay.io/org/image@sha256:... for quay.io/org/image:v1.
+// References that already include a digest are returned unchanged.
+func Pin(ctx context.Context, resolver Resolver, ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return ref, nil
+	}
+	dgst, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image %q: %w", ref, err)
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), dgst)
+	if err != nil {
+		return "", fmt.Errorf("failed to pin image %q: %w", ref, err)
+	}
+	return pinned.String(), nil
+}
+
+// normalize returns the fully-qualified form of ref, with the latest tag if it
+// has no tag, e.g. docker.io/library/nginx:latest for nginx.
+func normalize(ref string) (reference.Named, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	return reference.TagNameOnly(named), nil
+}
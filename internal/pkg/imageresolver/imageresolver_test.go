@@ -0,0 +1,93 @@
+package imageresolver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+var (
+	testDigest  = digest.FromString("image")
+	otherDigest = digest.FromString("other")
+)
+
+func Test_Mapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+quay.io/example/image:v1: `+testDigest.String()+`
+docker.io/library/nginx:latest: docker.io/library/nginx@`+otherDigest.String()+`
+`), 0o644))
+	resolver, err := NewMapping(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		ref         string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "digest value",
+			ref:      "quay.io/example/image:v1",
+			expected: "quay.io/example/image@" + testDigest.String(),
+		},
+		{
+			name:     "normalized reference",
+			ref:      "nginx",
+			expected: "docker.io/library/nginx@" + otherDigest.String(),
+		},
+		{
+			name:     "already pinned",
+			ref:      "quay.io/example/image@" + otherDigest.String(),
+			expected: "quay.io/example/image@" + otherDigest.String(),
+		},
+		{
+			name:        "not found",
+			ref:         "quay.io/example/image:v2",
+			expectedErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Pin(t.Context(), resolver, tt.ref)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func Test_NewMapping_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`quay.io/example/image:v1: quay.io/example/image:v2`), 0o644))
+	_, err := NewMapping(path)
+	require.ErrorContains(t, err, "has no digest")
+}
+
+func Test_Layout(t *testing.T) {
+	dir := t.TempDir()
+	store, err := oci.NewWithContext(t.Context(), dir)
+	require.NoError(t, err)
+	desc, err := oras.PushBytes(t.Context(), store, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(t.Context(), desc, "quay.io/example/image:v1"))
+
+	resolver, err := NewLayout(t.Context(), dir)
+	require.NoError(t, err)
+
+	actual, err := Pin(t.Context(), resolver, "quay.io/example/image:v1")
+	require.NoError(t, err)
+	require.Equal(t, "quay.io/example/image@"+desc.Digest.String(), actual)
+
+	_, err = Pin(t.Context(), resolver, "quay.io/example/image:v2")
+	require.ErrorIs(t, err, ErrNotFound)
+}
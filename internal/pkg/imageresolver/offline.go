@@ -0,0 +1,134 @@
+package imageresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+// ErrNotFound is returned by offline resolvers for references they have no
+// digest for.
+var ErrNotFound = errors.New("image not found")
+
+// candidates returns the forms of ref to look up in offline sources: ref as
+// written, and its fully-qualified form with an explicit tag.
+func candidates(ref string) ([]string, error) {
+	named, err := normalize(ref)
+	if err != nil {
+		return nil, err
+	}
+	if named.String() == ref {
+		return []string{ref}, nil
+	}
+	return []string{ref, named.String()}, nil
+}
+
+type layoutResolver struct {
+	target content.Resolver
+}
+
+// NewLayout returns a Resolver that resolves references using the tags of the
+// OCI image layout at path, which is either a directory or a tar archive such
+// as a kpm file. The layout is expected to be tagged with full image
+// references, e.g. quay.io/org/image:v1.
+func NewLayout(ctx context.Context, path string) (Resolver, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		store, err := ocilayout.OpenArchive(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		return &layoutResolver{target: store}, nil
+	}
+	store, err := oci.NewFromFS(ctx, os.DirFS(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OCI layout %q: %w", path, err)
+	}
+	return &layoutResolver{target: store}, nil
+}
+
+func (r *layoutResolver) Resolve(ctx context.Context, ref string) (digest.Digest, error) {
+	refs, err := candidates(ref)
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range refs {
+		desc, err := r.target.Resolve(ctx, candidate)
+		if err == nil {
+			return desc.Digest, nil
+		}
+	}
+	return "", fmt.Errorf("%w in OCI layout", ErrNotFound)
+}
+
+type mappingResolver struct {
+	mapping map[string]digest.Digest
+}
+
+// NewMapping returns a Resolver that resolves references using the YAML or
+// JSON mapping file at path, which maps image references to either their
+// digests or their digest references, e.g.
+//
+//	quay.io/org/image:v1: sha256:...
+//	quay.io/org/other:v2: quay.io/org/other@sha256:...
+func NewMapping(path string) (Resolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid image mapping file %q: %w", path, err)
+	}
+	mapping := make(map[string]digest.Digest, len(entries))
+	for ref, value := range entries {
+		dgst, err := mappedDigest(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image mapping file %q: %q: %w", path, ref, err)
+		}
+		mapping[ref] = dgst
+	}
+	return &mappingResolver{mapping: mapping}, nil
+}
+
+// mappedDigest returns the digest of a mapping file value, which is either a
+// digest or a digest reference.
+func mappedDigest(value string) (digest.Digest, error) {
+	if dgst, err := digest.Parse(value); err == nil {
+		return dgst, nil
+	}
+	named, err := reference.ParseNormalizedNamed(value)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither a digest nor an image reference", value)
+	}
+	digested, ok := named.(reference.Digested)
+	if !ok {
+		return "", fmt.Errorf("image reference %q has no digest", value)
+	}
+	return digested.Digest(), nil
+}
+
+func (r *mappingResolver) Resolve(_ context.Context, ref string) (digest.Digest, error) {
+	refs, err := candidates(ref)
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range refs {
+		if dgst, ok := r.mapping[candidate]; ok {
+			return dgst, nil
+		}
+	}
+	return "", fmt.Errorf("%w in mapping file", ErrNotFound)
+}
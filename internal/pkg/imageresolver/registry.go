@@ -0,0 +1,33 @@
+package imageresolver
+
+import (
+	"context"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+type registryResolver struct {
+	client    remote.Client
+	plainHTTP bool
+}
+
+// NewRegistry returns a Resolver that resolves references against their
+// registries using client.
+func NewRegistry(client remote.Client, plainHTTP bool) Resolver {
+	return &registryResolver{client: client, plainHTTP: plainHTTP}
+}
+
+func (r *registryResolver) Resolve(ctx context.Context, ref string) (digest.Digest, error) {
+	repo, err := registryclient.NewRepositoryWithClient(ref, r.client, r.plainHTTP)
+	if err != nil {
+		return "", err
+	}
+	desc, err := repo.Resolve(ctx, repo.Reference.ReferenceOrDefault())
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest, nil
+}
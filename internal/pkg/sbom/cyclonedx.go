@@ -0,0 +1,134 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	cycloneDXSpecVersion = "1.5"
+	cycloneDXBundleRef   = "package"
+
+	cycloneDXPropertyCRDGroup    = "kpm:crd:group"
+	cycloneDXPropertyCRDVersions = "kpm:crd:versions"
+)
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components,omitempty"`
+	Dependencies []cycloneDXDependency `json:"dependencies,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type               string                 `json:"type"`
+	BOMRef             string                 `json:"bom-ref,omitempty"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	PURL               string                 `json:"purl,omitempty"`
+	Hashes             []cycloneDXHash        `json:"hashes,omitempty"`
+	ExternalReferences []cycloneDXExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty    `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// cycloneDXHashAlgorithms maps digest algorithms to CycloneDX hash
+// algorithms.
+var cycloneDXHashAlgorithms = map[string]string{
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+func generateCycloneDX(inv Inventory, opts Options) ([]byte, error) {
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + serialNumber(inv).String(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: opts.Created.UTC().Format(time.RFC3339),
+			Tools: cycloneDXTools{Components: []cycloneDXComponent{
+				{Type: "application", Name: "kpm", Version: opts.ToolVersion},
+			}},
+			Component: cycloneDXComponent{
+				Type:    "application",
+				BOMRef:  cycloneDXBundleRef,
+				Name:    inv.Name,
+				Version: inv.Version,
+			},
+		},
+	}
+
+	bundleDependency := cycloneDXDependency{Ref: cycloneDXBundleRef}
+	for _, f := range inv.Files {
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			Type:   "file",
+			BOMRef: "file:" + f.Path,
+			Name:   f.Path,
+			Hashes: []cycloneDXHash{{
+				Alg:     cycloneDXHashAlgorithms[f.Digest.Algorithm().String()],
+				Content: f.Digest.Encoded(),
+			}},
+		})
+	}
+	for _, crd := range inv.CRDs {
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			Type:    "data",
+			BOMRef:  "crd:" + crd.Name,
+			Name:    crd.Name,
+			Version: strings.Join(crd.Versions, ","),
+			Properties: []cycloneDXProperty{
+				{Name: cycloneDXPropertyCRDGroup, Value: crd.Group},
+				{Name: cycloneDXPropertyCRDVersions, Value: strings.Join(crd.Versions, ",")},
+			},
+		})
+	}
+	for _, image := range inv.Images {
+		ref := "image:" + image
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			Type:               "container",
+			BOMRef:             ref,
+			Name:               image,
+			PURL:               imagePURL(image),
+			ExternalReferences: []cycloneDXExternalRef{{Type: "distribution", URL: image}},
+		})
+		bundleDependency.DependsOn = append(bundleDependency.DependsOn, ref)
+	}
+	bom.Dependencies = []cycloneDXDependency{bundleDependency}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
@@ -0,0 +1,133 @@
+// Package sbom generates software bills of materials (SBOMs) for packages in
+// the SPDX and CycloneDX formats.
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+
+	MediaTypeSPDX      = "application/spdx+json"
+	MediaTypeCycloneDX = "application/vnd.cyclonedx+json"
+)
+
+// Formats are the supported SBOM formats.
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// MediaType returns the media type of SBOMs in format, which is also the
+// artifact type of SBOMs attached to images.
+func MediaType(format string) (string, error) {
+	switch format {
+	case FormatSPDX:
+		return MediaTypeSPDX, nil
+	case FormatCycloneDX:
+		return MediaTypeCycloneDX, nil
+	default:
+		return "", fmt.Errorf("unknown SBOM format %q: must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// FormatForMediaType returns the format of SBOMs with the given media type,
+// or an empty string if it is not an SBOM media type.
+func FormatForMediaType(mediaType string) string {
+	switch mediaType {
+	case MediaTypeSPDX:
+		return FormatSPDX
+	case MediaTypeCycloneDX:
+		return FormatCycloneDX
+	default:
+		return ""
+	}
+}
+
+// Inventory is the content of a package that is described by an SBOM.
+type Inventory struct {
+	// Name and Version identify the package, which is the primary component
+	// of the SBOM.
+	Name    string
+	Version string
+	Files   []File
+	CRDs    []CRD
+	// Images are the container images the package references.
+	Images []string
+}
+
+// File is a file in the package.
+type File struct {
+	Path   string
+	Digest digest.Digest
+}
+
+// CRD is a CustomResourceDefinition in the package.
+type CRD struct {
+	Name     string
+	Group    string
+	Versions []string
+}
+
+// Options configure the generated SBOM.
+type Options struct {
+	// Created is the creation time of the SBOM.
+	Created time.Time
+	// ToolVersion is the version of kpm that generated the SBOM.
+	ToolVersion string
+}
+
+// Generate returns the SBOM of inv in format.
+func Generate(format string, inv Inventory, opts Options) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(inv, opts)
+	case FormatCycloneDX:
+		return generateCycloneDX(inv, opts)
+	default:
+		_, err := MediaType(format)
+		return nil, err
+	}
+}
+
+// serialNumber returns a UUID derived from the content of inv, so that the
+// SBOMs of identical packages have the same serial number.
+func serialNumber(inv Inventory) uuid.UUID {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", inv.Name, inv.Version)
+	for _, f := range inv.Files {
+		fmt.Fprintf(&b, "%s %s\n", f.Digest, f.Path)
+	}
+	for _, image := range inv.Images {
+		fmt.Fprintf(&b, "%s\n", image)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.String()))
+}
+
+// imagePURL returns the package URL of the container image ref, e.g.
+// pkg:oci/nginx@sha256:...?repository_url=docker.io/library/nginx&tag=1.25.
+// If ref cannot be parsed, it returns an empty string.
+func imagePURL(ref string) string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ""
+	}
+	repository := named.Name()
+	name := repository[strings.LastIndex(repository, "/")+1:]
+
+	purl := "pkg:oci/" + url.PathEscape(name)
+	if digested, ok := named.(reference.Digested); ok {
+		purl += "@" + url.PathEscape(digested.Digest().String())
+	}
+	qualifiers := url.Values{"repository_url": {repository}}
+	if tagged, ok := named.(reference.Tagged); ok {
+		qualifiers.Set("tag", tagged.Tag())
+	}
+	return purl + "?" + qualifiers.Encode()
+}
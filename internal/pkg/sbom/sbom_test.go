@@ -0,0 +1,109 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+var testInventory = Inventory{
+	Name:    "example",
+	Version: "1.2.3",
+	Files: []File{
+		{Path: "manifests/csv.yaml", Digest: digest.FromString("csv")},
+	},
+	CRDs: []CRD{
+		{Name: "resources.group.example.com", Group: "group.example.com", Versions: []string{"v1alpha1", "v1"}},
+	},
+	Images: []string{"quay.io/example/operator:v1.2.3"},
+}
+
+var testOptions = Options{
+	Created:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	ToolVersion: "v0.1.0",
+}
+
+func Test_Generate_SPDX(t *testing.T) {
+	data, err := Generate(FormatSPDX, testInventory, testOptions)
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "2025-01-02T03:04:05Z", doc.CreationInfo.Created)
+	require.Equal(t, []string{"Tool: kpm-v0.1.0"}, doc.CreationInfo.Creators)
+	require.Equal(t, []string{spdxPackageID}, doc.DocumentDescribes)
+
+	require.Len(t, doc.Packages, 3)
+	require.Equal(t, "example", doc.Packages[0].Name)
+	require.Equal(t, "1.2.3", doc.Packages[0].VersionInfo)
+	require.Equal(t, []string{"SPDXRef-File-manifests-csv.yaml"}, doc.Packages[0].HasFiles)
+	require.Equal(t, "resources.group.example.com", doc.Packages[1].Name)
+	require.Contains(t, doc.Packages[1].Comment, "group group.example.com")
+	require.Equal(t, []spdxExternalRef{{
+		ReferenceCategory: "PACKAGE-MANAGER",
+		ReferenceType:     "purl",
+		ReferenceLocator:  "pkg:oci/operator?repository_url=quay.io%2Fexample%2Foperator&tag=v1.2.3",
+	}}, doc.Packages[2].ExternalRefs)
+
+	require.Equal(t, []spdxFile{{
+		SPDXID:    "SPDXRef-File-manifests-csv.yaml",
+		FileName:  "./manifests/csv.yaml",
+		Checksums: []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: digest.FromString("csv").Encoded()}},
+	}}, doc.Files)
+	require.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: spdxPackageID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: doc.Packages[2].SPDXID})
+}
+
+func Test_Generate_CycloneDX(t *testing.T) {
+	data, err := Generate(FormatCycloneDX, testInventory, testOptions)
+	require.NoError(t, err)
+
+	var bom cycloneDXBOM
+	require.NoError(t, json.Unmarshal(data, &bom))
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "urn:uuid:"+serialNumber(testInventory).String(), bom.SerialNumber)
+	require.Equal(t, "example", bom.Metadata.Component.Name)
+	require.Equal(t, "1.2.3", bom.Metadata.Component.Version)
+
+	require.Len(t, bom.Components, 3)
+	require.Equal(t, []cycloneDXHash{{Alg: "SHA-256", Content: digest.FromString("csv").Encoded()}}, bom.Components[0].Hashes)
+	require.Equal(t, []cycloneDXProperty{
+		{Name: cycloneDXPropertyCRDGroup, Value: "group.example.com"},
+		{Name: cycloneDXPropertyCRDVersions, Value: "v1alpha1,v1"},
+	}, bom.Components[1].Properties)
+	require.Equal(t, "container", bom.Components[2].Type)
+	require.Equal(t, []cycloneDXExternalRef{{Type: "distribution", URL: "quay.io/example/operator:v1.2.3"}}, bom.Components[2].ExternalReferences)
+	require.Equal(t, []cycloneDXDependency{{Ref: cycloneDXBundleRef, DependsOn: []string{"image:quay.io/example/operator:v1.2.3"}}}, bom.Dependencies)
+}
+
+func Test_Generate_UnknownFormat(t *testing.T) {
+	_, err := Generate("swid", testInventory, testOptions)
+	require.ErrorContains(t, err, `unknown SBOM format "swid"`)
+}
+
+func Test_imagePURL(t *testing.T) {
+	for _, tc := range []struct {
+		ref      string
+		expected string
+	}{
+		{
+			ref:      "nginx",
+			expected: "pkg:oci/nginx?repository_url=docker.io%2Flibrary%2Fnginx",
+		},
+		{
+			ref:      "quay.io/example/operator:v1@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			expected: "pkg:oci/operator@sha256:1111111111111111111111111111111111111111111111111111111111111111?repository_url=quay.io%2Fexample%2Foperator&tag=v1",
+		},
+		{
+			ref:      "Invalid Reference",
+			expected: "",
+		},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			require.Equal(t, tc.expected, imagePURL(tc.ref))
+		})
+	}
+}
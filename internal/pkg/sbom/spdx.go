@@ -0,0 +1,149 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxPackageID   = "SPDXRef-Package"
+	spdxNoAssertion = "NOASSERTION"
+
+	spdxNamespacePrefix = "https://github.com/operator-framework/kpm/spdx/"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	HasFiles              []string          `json:"hasFiles,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxFile struct {
+	SPDXID    string         `json:"SPDXID"`
+	FileName  string         `json:"fileName"`
+	Checksums []spdxChecksum `json:"checksums"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// spdxID returns an SPDX identifier for an element of the given kind, e.g.
+// SPDXRef-File-manifests-csv.yaml.
+func spdxID(kind, name string) string {
+	return fmt.Sprintf("SPDXRef-%s-%s", kind, strings.Trim(spdxIDInvalidChars.ReplaceAllString(name, "-"), "-"))
+}
+
+func generateSPDX(inv Inventory, opts Options) ([]byte, error) {
+	name := fmt.Sprintf("%s-%s", inv.Name, inv.Version)
+	bundle := spdxPackage{
+		SPDXID:                spdxPackageID,
+		Name:                  inv.Name,
+		VersionInfo:           inv.Version,
+		DownloadLocation:      spdxNoAssertion,
+		FilesAnalyzed:         len(inv.Files) > 0,
+		PrimaryPackagePurpose: "APPLICATION",
+	}
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              name,
+		DocumentNamespace: spdxNamespacePrefix + name + "-" + serialNumber(inv).String(),
+		CreationInfo: spdxCreationInfo{
+			Created:  opts.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: kpm-" + opts.ToolVersion},
+		},
+		DocumentDescribes: []string{spdxPackageID},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxPackageID},
+		},
+	}
+
+	for _, f := range inv.Files {
+		id := spdxID("File", f.Path)
+		doc.Files = append(doc.Files, spdxFile{
+			SPDXID:   id,
+			FileName: "./" + f.Path,
+			Checksums: []spdxChecksum{{
+				Algorithm:     strings.ToUpper(f.Digest.Algorithm().String()),
+				ChecksumValue: f.Digest.Encoded(),
+			}},
+		})
+		bundle.HasFiles = append(bundle.HasFiles, id)
+	}
+
+	var packages []spdxPackage
+	for _, crd := range inv.CRDs {
+		id := spdxID("CRD", crd.Name)
+		packages = append(packages, spdxPackage{
+			SPDXID:           id,
+			Name:             crd.Name,
+			VersionInfo:      strings.Join(crd.Versions, ","),
+			DownloadLocation: spdxNoAssertion,
+			Comment:          fmt.Sprintf("CustomResourceDefinition of group %s with versions %s", crd.Group, strings.Join(crd.Versions, ", ")),
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: spdxPackageID, RelationshipType: "CONTAINS", RelatedSPDXElement: id})
+	}
+	for _, image := range inv.Images {
+		id := spdxID("Image", image)
+		pkg := spdxPackage{
+			SPDXID:                id,
+			Name:                  image,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: "CONTAINER",
+		}
+		if purl := imagePURL(image); purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+		}
+		packages = append(packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: spdxPackageID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id})
+	}
+	doc.Packages = append([]spdxPackage{bundle}, packages...)
+
+	return json.MarshalIndent(doc, "", "  ")
+}
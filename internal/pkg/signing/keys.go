@@ -0,0 +1,111 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// LoadPrivateKey loads an unencrypted ECDSA or ed25519 private key from the
+// PEM file at path. Both PKCS #8 ("PRIVATE KEY") and SEC 1 ("EC PRIVATE
+// KEY") encodings are supported.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM-encoded private key found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported private key type %q: keys must be unencrypted PKCS #8 or SEC 1 keys", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported private key algorithm %T: must be ECDSA or ed25519", path, key)
+	}
+}
+
+// LoadPublicKeys loads the ECDSA and ed25519 public keys from the PEM file at
+// path. A keyring file contains several keys, one per PEM block.
+func LoadPublicKeys(path string) ([]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey:
+		default:
+			return nil, fmt.Errorf("%s: unsupported public key algorithm %T: must be ECDSA or ed25519", path, key)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no PEM-encoded public keys found", path)
+	}
+	return keys, nil
+}
+
+// KeyID identifies a public key by the digest of its PKIX encoding.
+func KeyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(der).String(), nil
+}
+
+func verifySignature(key crypto.PublicKey, payload, signature []byte) error {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid ed25519 signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key algorithm %T", key)
+	}
+}
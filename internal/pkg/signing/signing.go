@@ -0,0 +1,178 @@
+// Package signing signs and verifies package images with local key pairs.
+// Signatures are stored as OCI referrers of the signed manifest using the
+// layout of cosign's simple signing format, so that they can be verified
+// with cosign as well as with kpm.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+const (
+	// ArtifactTypeSignature is the artifact type of signature referrers.
+	ArtifactTypeSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// MediaTypeSimpleSigning is the media type of the signed payload.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// AnnotationSignature is the annotation of the payload descriptor that
+	// holds the base64-encoded signature of the payload.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	simpleSigningType = "cosign container image signature"
+)
+
+// ErrUnverified is returned by Verify when no signature of the subject can be
+// verified with the given keys.
+var ErrUnverified = errors.New("no valid signatures found")
+
+// Payload is the signed payload in the simple signing format.
+type Payload struct {
+	Critical Critical          `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// Critical is the part of the payload that identifies the signed image.
+type Critical struct {
+	Identity Identity `json:"identity"`
+	Image    Image    `json:"image"`
+	Type     string   `json:"type"`
+}
+
+// Identity is the reference of the signed image, without a tag or digest.
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+// Image is the digest of the signed manifest.
+type Image struct {
+	DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+}
+
+// Signature is a verified signature of a manifest.
+type Signature struct {
+	// Descriptor describes the signature manifest.
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	// Identity is the signed image reference.
+	Identity string `json:"identity"`
+	// KeyID identifies the public key the signature was verified with.
+	KeyID string `json:"keyID"`
+}
+
+// Sign signs the manifest described by subject with signer and stores the
+// signature in target as a referrer of subject. identity is the reference
+// of the signed image without a tag or digest, e.g. quay.io/org/package.
+func Sign(ctx context.Context, target oras.Target, subject ocispec.Descriptor, identity string, signer crypto.Signer) (ocispec.Descriptor, error) {
+	payload, err := json.Marshal(Payload{
+		Critical: Critical{
+			Identity: Identity{DockerReference: identity},
+			Image:    Image{DockerManifestDigest: subject.Digest},
+			Type:     simpleSigningType,
+		},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	signature, err := sign(signer, payload)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to sign %s: %w", subject.Digest, err)
+	}
+
+	layer, err := oras.PushBytes(ctx, target, MediaTypeSimpleSigning, payload)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push signature payload: %w", err)
+	}
+	layer.Annotations = map[string]string{AnnotationSignature: base64.StdEncoding.EncodeToString(signature)}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, ArtifactTypeSignature, oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to attach signature to %s: %w", subject.Digest, err)
+	}
+	return desc, nil
+}
+
+// Verify returns the signatures of the manifest described by subject in
+// target that can be verified with any of keys. Signatures that cannot be
+// verified are ignored; if none can be verified, Verify returns
+// ErrUnverified.
+func Verify(ctx context.Context, target content.ReadOnlyGraphStorage, subject ocispec.Descriptor, keys []crypto.PublicKey) ([]Signature, error) {
+	referrers, err := ocilayout.Referrers(ctx, target, subject, ArtifactTypeSignature)
+	if err != nil {
+		return nil, err
+	}
+	var signatures []Signature
+	for _, referrer := range referrers {
+		layer, payload, err := ocilayout.FetchArtifact(ctx, target, referrer)
+		if err != nil {
+			return nil, err
+		}
+		identity, keyID, ok := verifyPayload(subject, layer, payload, keys)
+		if !ok {
+			continue
+		}
+		signatures = append(signatures, Signature{Descriptor: referrer, Identity: identity, KeyID: keyID})
+	}
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("%s: %w", subject.Digest, ErrUnverified)
+	}
+	return signatures, nil
+}
+
+// verifyPayload checks that payload is a simple signing payload of subject
+// whose signature in layer can be verified with one of keys, and returns the
+// signed identity and the ID of the key.
+func verifyPayload(subject, layer ocispec.Descriptor, payload []byte, keys []crypto.PublicKey) (string, string, bool) {
+	if layer.MediaType != MediaTypeSimpleSigning {
+		return "", "", false
+	}
+	signature, err := base64.StdEncoding.DecodeString(layer.Annotations[AnnotationSignature])
+	if err != nil || len(signature) == 0 {
+		return "", "", false
+	}
+	var p Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return "", "", false
+	}
+	if p.Critical.Type != simpleSigningType || p.Critical.Image.DockerManifestDigest != subject.Digest {
+		return "", "", false
+	}
+	for _, key := range keys {
+		if err := verifySignature(key, payload, signature); err != nil {
+			continue
+		}
+		keyID, err := KeyID(key)
+		if err != nil {
+			continue
+		}
+		return p.Critical.Identity.DockerReference, keyID, true
+	}
+	return "", "", false
+}
+
+func sign(signer crypto.Signer, payload []byte) ([]byte, error) {
+	switch signer.(type) {
+	case *ecdsa.PrivateKey:
+		hash := sha256.Sum256(payload)
+		return signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	case ed25519.PrivateKey:
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	default:
+		return nil, fmt.Errorf("unsupported private key algorithm %T", signer)
+	}
+}
@@ -0,0 +1,141 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func Test_SignVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		signer      crypto.Signer
+		keys        []crypto.PublicKey
+		expectedErr error
+	}{
+		{
+			name:   "ECDSA",
+			signer: ecKey,
+			keys:   []crypto.PublicKey{ecKey.Public()},
+		},
+		{
+			name:   "ed25519",
+			signer: edKey,
+			keys:   []crypto.PublicKey{edKey.Public()},
+		},
+		{
+			name:   "keyring",
+			signer: edKey,
+			keys:   []crypto.PublicKey{otherKey.Public(), edKey.Public()},
+		},
+		{
+			name:        "wrong key",
+			signer:      ecKey,
+			keys:        []crypto.PublicKey{otherKey.Public()},
+			expectedErr: ErrUnverified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := memory.New()
+			subject, err := oras.PushBytes(t.Context(), target, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+			require.NoError(t, err)
+
+			desc, err := Sign(t.Context(), target, subject, "quay.io/example/package", tt.signer)
+			require.NoError(t, err)
+
+			signatures, err := Verify(t.Context(), target, subject, tt.keys)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, signatures, 1)
+			require.Equal(t, desc.Digest, signatures[0].Descriptor.Digest)
+			require.Equal(t, "quay.io/example/package", signatures[0].Identity)
+			keyID, err := KeyID(tt.signer.Public())
+			require.NoError(t, err)
+			require.Equal(t, keyID, signatures[0].KeyID)
+		})
+	}
+}
+
+func Test_Verify_OtherSubject(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	target := memory.New()
+	signed, err := oras.PushBytes(t.Context(), target, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"a":1}`))
+	require.NoError(t, err)
+	unsigned, err := oras.PushBytes(t.Context(), target, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"b":1}`))
+	require.NoError(t, err)
+	_, err = Sign(t.Context(), target, signed, "example", key)
+	require.NoError(t, err)
+
+	_, err = Verify(t.Context(), target, unsigned, []crypto.PublicKey{key.Public()})
+	require.ErrorIs(t, err, ErrUnverified)
+}
+
+func Test_LoadKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePEM := func(name string, blocks ...*pem.Block) string {
+		var data []byte
+		for _, block := range blocks {
+			data = append(data, pem.EncodeToMemory(block)...)
+		}
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	ecSEC1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	ecPKIX, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+	edPKIX, err := x509.MarshalPKIXPublicKey(edPublic)
+	require.NoError(t, err)
+
+	signer, err := LoadPrivateKey(writePEM("ec.pem", &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecSEC1}))
+	require.NoError(t, err)
+	require.True(t, ecKey.Equal(signer))
+	signer, err = LoadPrivateKey(writePEM("ed.pem", &pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8}))
+	require.NoError(t, err)
+	require.True(t, edKey.Equal(signer))
+	_, err = LoadPrivateKey(writePEM("encrypted.pem", &pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: []byte("x")}))
+	require.ErrorContains(t, err, "unsupported private key type")
+
+	keys, err := LoadPublicKeys(writePEM("keyring.pub",
+		&pem.Block{Type: "PUBLIC KEY", Bytes: ecPKIX},
+		&pem.Block{Type: "PUBLIC KEY", Bytes: edPKIX},
+	))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, ecKey.PublicKey.Equal(keys[0]))
+	require.True(t, edPublic.Equal(keys[1]))
+	_, err = LoadPublicKeys(writePEM("empty.pub"))
+	require.ErrorContains(t, err, "no PEM-encoded public keys found")
+}
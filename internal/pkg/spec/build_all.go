@@ -0,0 +1,214 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+)
+
+// BuildResult is the outcome of building a single spec. Exactly one of
+// Report and Error is set.
+type BuildResult struct {
+	SpecFile string       `json:"specFile"`
+	Report   *BuildReport `json:"report,omitempty"`
+	Error    string       `json:"error,omitempty"`
+
+	err error
+}
+
+// NewFailedBuildResult returns the result of a spec file that failed before
+// its specs could be built, e.g. because it could not be loaded.
+func NewFailedBuildResult(specFile string, err error) BuildResult {
+	return BuildResult{SpecFile: specFile, Error: err.Error(), err: err}
+}
+
+func (r BuildResult) Err() error {
+	return r.err
+}
+
+// BuildResults are the results of building multiple specs.
+type BuildResults []BuildResult
+
+// Err returns the errors of all failed builds, or nil if every build
+// succeeded.
+func (r BuildResults) Err() error {
+	var errs []error
+	for _, result := range r {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.SpecFile, result.err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteFile writes a BuildReportList of the results to reportFile in the
+// given format (json or yaml). If reportFile is "-", the report is written to
+// stdout.
+func (r BuildResults) WriteFile(reportFile string, format string) error {
+	return writeReport(reportFile, format, r.reportList())
+}
+
+// Write writes a BuildReportList of the results to w in the given format
+// (json or yaml).
+func (r BuildResults) Write(w io.Writer, format string) error {
+	return encodeReport(w, format, r.reportList())
+}
+
+func (r BuildResults) reportList() any {
+	return struct {
+		metav1.TypeMeta `json:",inline"`
+		Results         BuildResults `json:"results"`
+	}{
+		TypeMeta: metav1.TypeMeta{APIVersion: specsv1.GroupVersion.String(), Kind: KindBuildReportList},
+		Results:  r,
+	}
+}
+
+// LoadSpecsFunc loads the specs defined by a spec file.
+type LoadSpecsFunc func(ctx context.Context, specFile string) ([]Spec, error)
+
+// BuildAll loads and builds the specs defined by specFiles concurrently,
+// running at most jobs loads or builds at a time. A failed load or build does
+// not stop the others. The results are in the order of the spec files and
+// the specs they define. The SpecFile of opts is set to each spec's file.
+func BuildAll(ctx context.Context, specFiles []string, load LoadSpecsFunc, jobs int, opts BuildOptions) BuildResults {
+	type loaded struct {
+		specs  []Spec
+		report *FileReport
+		err    error
+	}
+	loadedFiles := make([]loaded, len(specFiles))
+	forEach(len(specFiles), jobs, func(i int) {
+		specs, err := load(ctx, specFiles[i])
+		if err != nil {
+			loadedFiles[i] = loaded{err: err}
+			return
+		}
+		report, err := NewFileReport(specFiles[i])
+		loadedFiles[i] = loaded{specs: specs, report: report, err: err}
+	})
+
+	type request struct {
+		specFile string
+		report   *FileReport
+		spec     Spec
+	}
+	var (
+		results  BuildResults
+		requests []request
+		seen     = map[string]string{}
+	)
+	for i, specFile := range specFiles {
+		if err := loadedFiles[i].err; err != nil {
+			results = append(results, NewFailedBuildResult(specFile, err))
+			continue
+		}
+		for _, s := range loadedFiles[i].specs {
+			// Specs with the same ID would usually be written to the same
+			// output file, so they are rejected rather than built
+			// concurrently.
+			id := s.ID()
+			if other, ok := seen[id]; ok {
+				results = append(results, NewFailedBuildResult(specFile, fmt.Errorf("spec %s is also defined by %s", id, other)))
+				continue
+			}
+			seen[id] = specFile
+			results = append(results, BuildResult{SpecFile: specFile})
+			requests = append(requests, request{specFile: specFile, report: loadedFiles[i].report, spec: s})
+		}
+	}
+
+	built := make(BuildResults, len(requests))
+	prepared := make([]*PreparedBuild, len(requests))
+	forEach(len(requests), jobs, func(i int) {
+		specOpts := opts
+		specOpts.SpecFile = requests[i].report
+		b, err := Prepare(ctx, requests[i].spec, specOpts)
+		if err != nil {
+			built[i] = NewFailedBuildResult(requests[i].specFile, err)
+			return
+		}
+		prepared[i] = b
+	})
+
+	// Output file names may not depend on the ID, so specs can still have
+	// the same output files. Fail all but the first before anything is
+	// written, so that the surviving files are those of the first.
+	written := map[string]string{}
+	for i, b := range prepared {
+		if b == nil {
+			continue
+		}
+		id := b.report.ID
+		var err error
+		for _, f := range b.OutputFiles() {
+			if other, ok := written[outputFileKey(f)]; ok {
+				err = fmt.Errorf("output file %s of %s is also an output file of %s", f, id, other)
+				break
+			}
+		}
+		if err != nil {
+			built[i] = NewFailedBuildResult(requests[i].specFile, err)
+			_ = b.Close()
+			prepared[i] = nil
+			continue
+		}
+		for _, f := range b.OutputFiles() {
+			written[outputFileKey(f)] = id
+		}
+	}
+
+	forEach(len(requests), jobs, func(i int) {
+		b := prepared[i]
+		if b == nil {
+			return
+		}
+		defer b.Close()
+		report, err := b.Write()
+		if err != nil {
+			built[i] = NewFailedBuildResult(requests[i].specFile, err)
+			return
+		}
+		built[i] = BuildResult{SpecFile: requests[i].specFile, Report: report}
+	})
+
+	// Fill in the build results in place of the placeholders.
+	next := 0
+	for i := range results {
+		if results[i].err == nil {
+			results[i] = built[next]
+			next++
+		}
+	}
+	return results
+}
+
+// outputFileKey returns the absolute path of the output file f, so that
+// different relative paths of the same file are recognized as such.
+func outputFileKey(f string) string {
+	if abs, err := filepath.Abs(f); err == nil {
+		return abs
+	}
+	return filepath.Clean(f)
+}
+
+// forEach calls fn for each index in [0, n), running at most jobs calls
+// concurrently.
+func forEach(n, jobs int, fn func(int)) {
+	var eg errgroup.Group
+	eg.SetLimit(max(jobs, 1))
+	for i := range n {
+		eg.Go(func() error {
+			fn(i)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+}
@@ -0,0 +1,159 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type testSpec struct {
+	id  string
+	err error
+}
+
+func (s testSpec) ID() string {
+	return s.id
+}
+
+func (s testSpec) MarshalOCI(ctx context.Context, target oras.Target) (ocispec.Descriptor, error) {
+	if s.err != nil {
+		return ocispec.Descriptor{}, s.err
+	}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, "application/vnd.example.test", oras.PackManifestOptions{
+		ManifestAnnotations: map[string]string{"id": s.id},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, target.Tag(ctx, desc, s.id+":latest")
+}
+
+func Test_BuildAll(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	specFiles := map[string][]Spec{
+		"a.kpmspec.yaml":     {testSpec{id: "a"}},
+		"multi.kpmspec.star": {testSpec{id: "b"}, testSpec{id: "c", err: errors.New("marshal failed")}, testSpec{id: "d"}},
+		"dup.kpmspec.yaml":   {testSpec{id: "a"}},
+	}
+	for specFile := range specFiles {
+		require.NoError(t, os.WriteFile(specFile, []byte(specFile), 0600))
+	}
+	load := func(_ context.Context, specFile string) ([]Spec, error) {
+		specs, ok := specFiles[specFile]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return specs, nil
+	}
+
+	results := BuildAll(t.Context(), []string{"a.kpmspec.yaml", "missing.kpmspec.yaml", "multi.kpmspec.star", "dup.kpmspec.yaml"}, load, 2, BuildOptions{})
+
+	type outcome struct {
+		specFile string
+		id       string
+		err      string
+	}
+	var actual []outcome
+	for _, r := range results {
+		o := outcome{specFile: r.SpecFile, err: r.Error}
+		if r.Report != nil {
+			o.id = r.Report.ID
+			require.FileExists(t, r.Report.OutputFile)
+			require.Equal(t, r.SpecFile, r.Report.SpecFile.Path)
+			require.Equal(t, digest.FromString(r.SpecFile), r.Report.SpecFile.Digest)
+		}
+		actual = append(actual, o)
+	}
+	require.Equal(t, []outcome{
+		{specFile: "a.kpmspec.yaml", id: "a"},
+		{specFile: "missing.kpmspec.yaml", err: "not found"},
+		{specFile: "multi.kpmspec.star", id: "b"},
+		{specFile: "multi.kpmspec.star", err: "marshal failed"},
+		{specFile: "multi.kpmspec.star", id: "d"},
+		{specFile: "dup.kpmspec.yaml", err: "spec a is also defined by a.kpmspec.yaml"},
+	}, actual)
+
+	err := results.Err()
+	require.ErrorContains(t, err, "missing.kpmspec.yaml: not found")
+	require.ErrorContains(t, err, "multi.kpmspec.star: marshal failed")
+	require.ErrorContains(t, err, "dup.kpmspec.yaml: spec a is also defined by a.kpmspec.yaml")
+}
+
+func Test_BuildAll_OutputFileCollision(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	specFiles := map[string][]Spec{
+		"a.kpmspec.yaml": {testSpec{id: "a"}},
+		"b.kpmspec.yaml": {testSpec{id: "b"}},
+	}
+	for specFile := range specFiles {
+		require.NoError(t, os.WriteFile(specFile, []byte(specFile), 0600))
+	}
+	load := func(_ context.Context, specFile string) ([]Spec, error) {
+		return specFiles[specFile], nil
+	}
+
+	results := BuildAll(t.Context(), []string{"a.kpmspec.yaml", "b.kpmspec.yaml"}, load, 2, BuildOptions{
+		Output:     OutputOptions{File: "out.kpm"},
+		Provenance: ProvenanceOptions{Enabled: true},
+	})
+	require.Len(t, results, 2)
+	require.NotNil(t, results[0].Report)
+	require.Equal(t, "b.kpmspec.yaml", results[1].SpecFile)
+	require.Equal(t, "output file out.kpm of b is also an output file of a", results[1].Error)
+
+	// The surviving files are those of the first spec, not a mix of both.
+	expected := results[0].Report.Descriptor.Digest
+	store, err := ocilayout.OpenArchive(t.Context(), "out.kpm")
+	require.NoError(t, err)
+	desc, err := store.Resolve(t.Context(), "a:latest")
+	require.NoError(t, err)
+	require.Equal(t, expected, desc.Digest)
+	_, err = store.Resolve(t.Context(), "b:latest")
+	require.Error(t, err)
+
+	provenance, err := os.ReadFile(results[0].Report.ProvenanceFile)
+	require.NoError(t, err)
+	require.Contains(t, string(provenance), expected.Encoded())
+}
+
+func Test_BuildAll_OutputFileCollisionAcrossDirectories(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.Mkdir("specs", 0o755))
+
+	// The specs are in different directories and refer to the same output
+	// file by a relative and an absolute path.
+	specFiles := map[string][]Spec{
+		"a.kpmspec.yaml": {testOutputSpec{testSpec: testSpec{id: "a"}, output: OutputOptions{
+			Directory: "out",
+			File:      "bundle.kpm",
+		}}},
+		filepath.Join("specs", "b.kpmspec.yaml"): {testOutputSpec{testSpec: testSpec{id: "b"}, output: OutputOptions{
+			Directory: filepath.Join(dir, "specs", "..", "out"),
+			File:      "bundle.kpm",
+		}}},
+	}
+	for specFile := range specFiles {
+		require.NoError(t, os.WriteFile(specFile, []byte(specFile), 0600))
+	}
+	load := func(_ context.Context, specFile string) ([]Spec, error) {
+		return specFiles[specFile], nil
+	}
+
+	results := BuildAll(t.Context(), []string{"a.kpmspec.yaml", filepath.Join("specs", "b.kpmspec.yaml")}, load, 2, BuildOptions{})
+	require.Len(t, results, 2)
+	require.NotNil(t, results[0].Report)
+	require.Equal(t, fmt.Sprintf("output file %s of b is also an output file of a", filepath.Join(dir, "out", "bundle.kpm")), results[1].Error)
+}
@@ -5,66 +5,223 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/google/renameio/v2"
-	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"oras.land/oras-go/v2/content/oci"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/operator-framework/kpm/internal/pkg/util/tar"
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/version"
 )
 
-type BuildReport struct {
-	ID         string             `json:"id"`
-	Descriptor ocispec.Descriptor `json:"descriptor"`
-	OutputFile string             `json:"outputFile"`
+// BuildOptions configure how a spec is built.
+type BuildOptions struct {
+	// SpecFile is the spec file that defined the spec, if any.
+	SpecFile *FileReport
+	// Output overrides the output options of the spec.
+	Output     OutputOptions
+	Provenance ProvenanceOptions
+	SBOM       SBOMOptions
+	// ImageResolver, if set, is used to pin the images referenced by the
+	// spec to digests before it is marshaled.
+	ImageResolver imageresolver.Resolver
 }
 
-func (r BuildReport) WriteFile(reportFile string) error {
-	reportData, err := json.MarshalIndent(r, "", "  ")
+// Build marshals spec into a kpm file. The output options of spec, if it
+// is an OutputSpec, are merged with opts.Output to determine the file path
+// and any additional tags.
+func Build(ctx context.Context, spec Spec, opts BuildOptions) (*BuildReport, error) {
+	b, err := Prepare(ctx, spec, opts)
 	if err != nil {
-		return fmt.Errorf("failed to marshal report: %v", err)
-	}
-	if reportFile == "-" || reportFile == "/dev/stdout" {
-		fmt.Println(string(reportData))
+		return nil, err
 	}
-	return renameio.WriteFile(reportFile, reportData, 0644)
+	defer b.Close()
+	return b.Write()
 }
 
-func Build(ctx context.Context, spec Spec) (*BuildReport, error) {
-	tmpDir, err := os.MkdirTemp("", "kpm-build-bundle-*")
+// PreparedBuild is a spec that has been marshaled, tagged and described, but
+// whose output files have not been written yet, so that the output files of
+// several builds can be checked before any of them is written. It must be
+// closed.
+type PreparedBuild struct {
+	report     *BuildReport
+	layout     *ocilayout.TempLayout
+	provenance []byte
+	sboms      []generatedSBOM
+	started    time.Time
+	marshaled  time.Time
+}
+
+// Prepare marshals spec into a temporary OCI layout and determines its
+// output files, like Build, without writing them.
+func Prepare(ctx context.Context, spec Spec, opts BuildOptions) (*PreparedBuild, error) {
+	started := time.Now()
+	id := spec.ID()
+	fields := OutputFields{ID: id}
+	output := opts.Output
+	if outputSpec, ok := spec.(OutputSpec); ok {
+		fields = outputSpec.OutputFields()
+		output = outputSpec.OutputOptions().Merge(output)
+	}
+
+	var pinnedImages []imageresolver.PinnedImage
+	if opts.ImageResolver != nil {
+		var err error
+		if pinnedImages, err = pinImages(ctx, spec, opts.ImageResolver); err != nil {
+			return nil, err
+		}
+	}
+
+	layout, err := ocilayout.NewTempLayout(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer os.RemoveAll(tmpDir)
+	b := &PreparedBuild{layout: layout, started: started}
+	if err := b.prepare(ctx, spec, opts, fields, output, pinnedImages); err != nil {
+		_ = layout.Close()
+		return nil, err
+	}
+	return b, nil
+}
 
-	ociLayout, err := oci.NewWithContext(ctx, tmpDir)
+func (b *PreparedBuild) prepare(ctx context.Context, spec Spec, opts BuildOptions, fields OutputFields, output OutputOptions, pinnedImages []imageresolver.PinnedImage) error {
+	id := spec.ID()
+	desc, err := spec.MarshalOCI(ctx, b.layout)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	report := newBuildReport()
+	report.ID = id
+	report.SpecFile = opts.SpecFile
+	report.KPMVersion = version.Version()
+	report.Descriptor = desc
+	report.PinnedImages = pinnedImages
+	if warningsSpec, ok := spec.(WarningsSpec); ok {
+		report.Warnings = warningsSpec.Warnings()
 	}
+	if revisionSpec, ok := spec.(SourceRevisionSpec); ok {
+		report.SourceRevision = revisionSpec.SourceRevision()
+	}
+	if err := report.describeImage(ctx, b.layout); err != nil {
+		return err
+	}
+	b.marshaled = time.Now()
+	fields.Digest = desc.Digest
 
-	desc, err := spec.MarshalOCI(ctx, ociLayout)
+	tags, err := output.tags(fields)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if len(tags) > 0 && fields.Name == "" {
+		return fmt.Errorf("cannot apply tags to %s: image has no name", id)
+	}
+	for _, tag := range tags {
+		if err := b.layout.Tag(ctx, desc, fmt.Sprintf("%s:%s", fields.Name, tag)); err != nil {
+			return fmt.Errorf("failed to tag %s with %q: %v", id, tag, err)
+		}
 	}
 
-	id := spec.ID()
-	outputFile := fmt.Sprintf("%s.kpm", id)
-	pf, err := renameio.NewPendingFile(outputFile)
+	if opts.Provenance.Enabled || opts.Provenance.Attach {
+		name := fields.Name
+		if name == "" {
+			name = id
+		}
+		statement, err := newProvenance(spec, name, desc, opts.SpecFile, b.started)
+		if err != nil {
+			return fmt.Errorf("failed to generate provenance: %w", err)
+		}
+		if b.provenance, err = json.MarshalIndent(statement, "", "  "); err != nil {
+			return err
+		}
+		if opts.Provenance.Attach {
+			referrer, err := attachProvenance(ctx, b.layout, desc, b.provenance)
+			if err != nil {
+				return err
+			}
+			report.Referrers = append(report.Referrers, referrer)
+		}
+	}
+
+	var sboms []generatedSBOM
+	if formats := opts.SBOM.formats(); len(formats) > 0 {
+		if sboms, err = generateSBOMs(spec, formats, b.started); err != nil {
+			return fmt.Errorf("failed to generate SBOM: %w", err)
+		}
+	}
+	if opts.SBOM.Attach {
+		for _, s := range sboms {
+			referrer, err := attachSBOM(ctx, b.layout, desc, s)
+			if err != nil {
+				return err
+			}
+			report.Referrers = append(report.Referrers, referrer)
+		}
+	}
+
+	outputFile, err := output.outputFile(fields)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	report.OutputFile = outputFile
+	report.Tags = tags
+	if b.provenance != nil {
+		report.ProvenanceFile = provenanceFile(outputFile)
 	}
-	defer pf.Cleanup()
+	// Attached SBOMs are only written to files if formats were requested.
+	if len(opts.SBOM.Formats) > 0 {
+		b.sboms = sboms
+		for _, s := range sboms {
+			report.SBOMFiles = append(report.SBOMFiles, sbomFile(outputFile, s.format))
+		}
+	}
+	b.report = report
+	return nil
+}
 
-	if err := tar.Directory(pf, os.DirFS(tmpDir)); err != nil {
-		return nil, err
+// OutputFiles returns the paths of the files that Write writes: the kpm
+// file, followed by its provenance and SBOM files, if any.
+func (b *PreparedBuild) OutputFiles() []string {
+	files := []string{b.report.OutputFile}
+	if b.report.ProvenanceFile != "" {
+		files = append(files, b.report.ProvenanceFile)
 	}
-	if err := pf.CloseAtomicallyReplace(); err != nil {
+	return append(files, b.report.SBOMFiles...)
+}
+
+// Write writes the output files of the build and returns its report.
+func (b *PreparedBuild) Write() (*BuildReport, error) {
+	report := b.report
+	if dir := filepath.Dir(report.OutputFile); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.layout.WriteArchive(report.OutputFile); err != nil {
 		return nil, err
 	}
+	if b.provenance != nil {
+		if err := writeProvenance(report.ProvenanceFile, b.provenance); err != nil {
+			return nil, err
+		}
+	}
+	for i, s := range b.sboms {
+		if err := writeSBOM(report.SBOMFiles[i], s.data); err != nil {
+			return nil, err
+		}
+	}
+	finished := time.Now()
+
+	report.Timings = BuildTimings{
+		Started: metav1.NewTime(b.started),
+		Marshal: metav1.Duration{Duration: b.marshaled.Sub(b.started)},
+		Write:   metav1.Duration{Duration: finished.Sub(b.marshaled)},
+		Total:   metav1.Duration{Duration: finished.Sub(b.started)},
+	}
+	return report, nil
+}
 
-	return &BuildReport{
-		ID:         id,
-		Descriptor: desc,
-		OutputFile: outputFile,
-	}, nil
+// Close removes the temporary OCI layout of the build.
+func (b *PreparedBuild) Close() error {
+	return b.layout.Close()
 }
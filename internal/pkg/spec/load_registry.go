@@ -1,7 +1,9 @@
 package spec
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -23,7 +25,23 @@ func NewLoaderRegistry() *Registry {
 	return &Registry{reg: make(map[schema.GroupVersionKind]LoadSpecFunc)}
 }
 
-type LoadSpecFunc func([]byte, string) (Spec, error)
+// LoadOptions configure how specs load their sources.
+type LoadOptions struct {
+	// PlainHTTP uses plain HTTP instead of HTTPS to connect to image
+	// registries.
+	PlainHTTP bool
+
+	// Root, if set, confines the spec to Root, which is opened at the
+	// working directory, as for specs returned by the programs of Starlark
+	// spec files. Source paths must be local to the working directory and
+	// are read through Root, so that symlinks cannot escape it either, and
+	// sources that can read other files or reach the network are rejected.
+	Root *os.Root
+}
+
+// LoadSpecFunc loads a spec from its data. Relative paths in the spec are
+// relative to workingDir, and ctx is used to fetch remote sources.
+type LoadSpecFunc func(ctx context.Context, specData []byte, workingDir string, opts LoadOptions) (Spec, error)
 
 func (r *Registry) RegisterKind(gvk schema.GroupVersionKind, loadSpecFunc LoadSpecFunc) error {
 	r.mu.Lock()
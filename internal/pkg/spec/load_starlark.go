@@ -0,0 +1,359 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/syntax"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// StarlarkSpecFileSuffix is the file name suffix of Starlark spec files.
+const StarlarkSpecFileSuffix = ".kpmspec.star"
+
+// starlarkSpecsFunc is the name of the function that Starlark spec files
+// must define. It takes no arguments and returns a spec object or a list of
+// spec objects.
+const starlarkSpecsFunc = "specs"
+
+// Starlark loads Starlark spec files. A Starlark spec file is a program that
+// defines a specs() function returning one or more spec objects (dicts with
+// apiVersion and kind keys), each of which is loaded with the Registry
+// loader for its GVK.
+//
+// Programs are sandboxed: they can only read files within the spec file's
+// directory, and they have no access to the network, the environment, or the
+// clock. Programs are stopped after starlarkMaxExecutionSteps steps or when
+// the context is cancelled. The specs they return are confined to the spec
+// file's directory as well (see LoadOptions.Root). The following builtins are available in addition to the Starlark
+// standard library:
+//
+//	glob(pattern)     sorted list of paths matching pattern
+//	read_file(path)   contents of the file at path, as a string
+//	read_yaml(path)   contents of the YAML or JSON file at path
+//	json              the Starlark json module
+//	values            the Values dict
+//
+// Paths are relative to the spec file's directory.
+type Starlark struct {
+	Registry *Registry
+
+	// Values are available to programs as the values dict.
+	Values map[string]any
+
+	// Load configures how specs load their sources.
+	Load LoadOptions
+}
+
+func (l *Starlark) LoadSpecFile(ctx context.Context, path string) ([]Spec, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	workingDir := filepath.Dir(path)
+	root, err := os.OpenRoot(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	objs, err := evalStarlarkSpecs(ctx, path, src, root.FS(), l.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := l.Load
+	opts.Root = root
+	specs := make([]Spec, 0, len(objs))
+	for i, obj := range objs {
+		s, err := l.loadSpecObject(ctx, obj, workingDir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: spec %d: %w", path, i, err)
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+func (l *Starlark) loadSpecObject(ctx context.Context, obj map[string]any, workingDir string, opts LoadOptions) (Spec, error) {
+	specData, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var partial metav1.PartialObjectMetadata
+	if err := yaml.Unmarshal(specData, &partial); err != nil {
+		return nil, err
+	}
+	gvk := partial.GroupVersionKind()
+	loadSpecFunc, err := l.Registry.GetLoadSpecFunc(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spec loader from registry for GVK %q: %w", gvk, err)
+	}
+	return loadSpecFunc(ctx, specData, workingDir, opts)
+}
+
+// starlarkMaxExecutionSteps bounds the computation of a Starlark spec file,
+// so that a runaway program fails rather than hanging the build.
+var starlarkMaxExecutionSteps uint64 = 100_000_000
+
+func evalStarlarkSpecs(ctx context.Context, path string, src []byte, fsys fs.FS, values map[string]any) ([]map[string]any, error) {
+	if values == nil {
+		values = map[string]any{}
+	}
+	starlarkValues, err := toStarlark(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %v", err)
+	}
+	predeclared := starlark.StringDict{
+		"glob":      starlark.NewBuiltin("glob", starlarkGlob(fsys)),
+		"read_file": starlark.NewBuiltin("read_file", starlarkReadFile(fsys)),
+		"read_yaml": starlark.NewBuiltin("read_yaml", starlarkReadYAML(fsys)),
+		"json":      starlarkjson.Module,
+		"values":    starlarkValues,
+	}
+
+	thread := &starlark.Thread{
+		Name: path,
+		Load: func(*starlark.Thread, string) (starlark.StringDict, error) {
+			return nil, errors.New("load is not supported in spec files")
+		},
+	}
+	thread.SetMaxExecutionSteps(starlarkMaxExecutionSteps)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(context.Cause(ctx).Error())
+		case <-done:
+		}
+	}()
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, predeclared)
+	if err != nil {
+		return nil, starlarkError(err)
+	}
+	fn, ok := globals[starlarkSpecsFunc].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s: must define a %s() function", path, starlarkSpecsFunc)
+	}
+	result, err := starlark.Call(thread, fn, nil, nil)
+	if err != nil {
+		return nil, starlarkError(err)
+	}
+
+	goResult, err := fromStarlark(result)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid result of %s(): %v", path, starlarkSpecsFunc, err)
+	}
+	var items []any
+	switch v := goResult.(type) {
+	case map[string]any:
+		items = []any{v}
+	case []any:
+		items = v
+	default:
+		return nil, fmt.Errorf("%s: %s() must return a dict or a list of dicts, got %s", path, starlarkSpecsFunc, result.Type())
+	}
+	objs := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s() result %d is not a dict", path, starlarkSpecsFunc, i)
+		}
+		objs = append(objs, obj)
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("%s: %s() returned no specs", path, starlarkSpecsFunc)
+	}
+	return objs, nil
+}
+
+func starlarkError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+type starlarkBuiltinFunc = func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error)
+
+func starlarkGlob(fsys fs.FS) starlarkBuiltinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var pattern string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &pattern); err != nil {
+			return nil, err
+		}
+		matches, err := fs.Glob(fsys, cleanStarlarkPath(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		slices.Sort(matches)
+		elems := make([]starlark.Value, 0, len(matches))
+		for _, m := range matches {
+			elems = append(elems, starlark.String(m))
+		}
+		return starlark.NewList(elems), nil
+	}
+}
+
+func starlarkReadFile(fsys fs.FS) starlarkBuiltinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, cleanStarlarkPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		return starlark.String(data), nil
+	}
+}
+
+func starlarkReadYAML(fsys fs.FS) starlarkBuiltinFunc {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &path); err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, cleanStarlarkPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", b.Name(), path, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(jsonData))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", b.Name(), path, err)
+		}
+		return toStarlark(v)
+	}
+}
+
+// cleanStarlarkPath converts a path relative to the spec file's directory to
+// an io/fs path. Paths that escape the directory are left invalid, so that
+// the filesystem rejects them.
+func cleanStarlarkPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+func toStarlark(v any) (starlark.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return starlark.MakeInt64(i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Float(f), nil
+	case []any:
+		elems := make([]starlark.Value, 0, len(v))
+		for _, e := range v {
+			sv, err := toStarlark(e)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, sv)
+		}
+		return starlark.NewList(elems), nil
+	case map[string]any:
+		d := starlark.NewDict(len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			sv, err := toStarlark(v[k])
+			if err != nil {
+				return nil, err
+			}
+			if err := d.SetKey(starlark.String(k), sv); err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func fromStarlark(v starlark.Value) (any, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("integer %s is out of range", v)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(v), nil
+	case *starlark.List:
+		return fromStarlarkIterable(v)
+	case starlark.Tuple:
+		return fromStarlarkIterable(v)
+	case *starlark.Dict:
+		m := make(map[string]any, v.Len())
+		for _, item := range v.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict key %s is not a string", item[0])
+			}
+			gv, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = gv
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", v.Type())
+	}
+}
+
+func fromStarlarkIterable(v starlark.Iterable) ([]any, error) {
+	it := v.Iterate()
+	defer it.Done()
+	out := []any{}
+	var elem starlark.Value
+	for it.Next(&elem) {
+		gv, err := fromStarlark(elem)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, gv)
+	}
+	return out, nil
+}
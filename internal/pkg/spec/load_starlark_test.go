@@ -0,0 +1,247 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	tarutil "github.com/operator-framework/kpm/internal/pkg/util/tar"
+)
+
+func Test_evalStarlarkSpecs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bundles/1.0.0/metadata/annotations.yaml": &fstest.MapFile{},
+		"bundles/2.0.0/metadata/annotations.yaml": &fstest.MapFile{},
+		"settings.yaml": &fstest.MapFile{Data: []byte("kind: RegistryV1\nreplicas: 3\n")},
+		"version.txt":   &fstest.MapFile{Data: []byte("2.0.0\n")},
+	}
+
+	tests := []struct {
+		name      string
+		src       string
+		values    map[string]any
+		expected  []map[string]any
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "single spec",
+			src: `
+def specs():
+    return {"kind": read_yaml("settings.yaml")["kind"], "version": read_file("version.txt").strip()}
+`,
+			expected:  []map[string]any{{"kind": "RegistryV1", "version": "2.0.0"}},
+			assertErr: require.NoError,
+		},
+		{
+			name: "multiple specs from glob and values",
+			src: `
+def specs():
+    return [{"path": p, "channel": values["channel"]} for p in glob("bundles/*")]
+`,
+			values: map[string]any{"channel": "stable"},
+			expected: []map[string]any{
+				{"path": "bundles/1.0.0", "channel": "stable"},
+				{"path": "bundles/2.0.0", "channel": "stable"},
+			},
+			assertErr: require.NoError,
+		},
+		{
+			name: "yaml numbers are integers",
+			src: `
+def specs():
+    return {"replicas": read_yaml("settings.yaml")["replicas"] + 1}
+`,
+			expected:  []map[string]any{{"replicas": int64(4)}},
+			assertErr: require.NoError,
+		},
+		{
+			name: "no specs function",
+			src:  `x = 1`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "must define a specs() function")
+			},
+		},
+		{
+			name: "invalid result",
+			src: `
+def specs():
+    return ["not a dict"]
+`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "result 0 is not a dict")
+			},
+		},
+		{
+			name: "reading outside the spec directory",
+			src:  `x = read_file("../secret")`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "read_file: open ../secret")
+			},
+		},
+		{
+			name: "load is not supported",
+			src:  `load("other.star", "x")`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "load is not supported")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := evalStarlarkSpecs(t.Context(), "test.kpmspec.star", []byte(tt.src), fsys, tt.values)
+			tt.assertErr(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func Test_evalStarlarkSpecs_Limits(t *testing.T) {
+	src := []byte(`
+def specs():
+    n = 0
+    for _ in range(1000000000):
+        n += 1
+    return {"n": n}
+`)
+
+	t.Run("execution steps", func(t *testing.T) {
+		defer func(steps uint64) { starlarkMaxExecutionSteps = steps }(starlarkMaxExecutionSteps)
+		starlarkMaxExecutionSteps = 1000
+		_, err := evalStarlarkSpecs(t.Context(), "test.kpmspec.star", src, fstest.MapFS{}, nil)
+		require.ErrorContains(t, err, "too many steps")
+	})
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := evalStarlarkSpecs(ctx, "test.kpmspec.star", src, fstest.MapFS{}, nil)
+		require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	})
+}
+
+func Test_Starlark_LoadSpecFile_Confined(t *testing.T) {
+	dir := t.TempDir()
+	specDir := filepath.Join(dir, "specs")
+	outsideDir := filepath.Join(dir, "outside")
+	for _, d := range []string{specDir, outsideDir} {
+		writeTestBundle(t, filepath.Join(d, "bundle"))
+		var tarData bytes.Buffer
+		require.NoError(t, tarutil.Directory(&tarData, os.DirFS(filepath.Join(d, "bundle"))))
+		require.NoError(t, os.WriteFile(filepath.Join(d, "bundle.tar"), tarData.Bytes(), 0o644))
+
+		repo, err := git.PlainInit(filepath.Join(d, "repo"), false)
+		require.NoError(t, err)
+		writeTestBundle(t, filepath.Join(d, "repo", "bundle"))
+		wt, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = wt.Add(".")
+		require.NoError(t, err)
+		_, err = wt.Commit("bundle", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Symlink(filepath.Join("..", "outside", "bundle"), filepath.Join(specDir, "bundle-link")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "outside", "bundle.tar"), filepath.Join(specDir, "bundle-link.tar")))
+	require.NoError(t, os.MkdirAll(filepath.Join(specDir, "repo-link"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join("..", "..", "outside", "repo", ".git"), filepath.Join(specDir, "repo-link", ".git")))
+
+	specFile := filepath.Join(specDir, "bundle"+StarlarkSpecFileSuffix)
+	require.NoError(t, os.WriteFile(specFile, []byte("def specs():\n    return values[\"spec\"]\n"), 0o644))
+
+	tests := []struct {
+		name        string
+		source      map[string]any
+		expectedErr string
+	}{
+		{
+			name:   "bundle directory",
+			source: map[string]any{"sourceType": specsv1.RegistryV1SourceTypeBundleDirectory, "bundleDirectory": map[string]any{"path": "bundle"}},
+		},
+		{
+			name:        "bundle directory outside the spec directory",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeBundleDirectory, "bundleDirectory": map[string]any{"path": "../outside/bundle"}},
+			expectedErr: `invalid source.bundleDirectory.path "../outside/bundle": must be a local path`,
+		},
+		{
+			name:        "absolute bundle directory",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeBundleDirectory, "bundleDirectory": map[string]any{"path": filepath.Join(outsideDir, "bundle")}},
+			expectedErr: "must be a local path",
+		},
+		{
+			name:        "bundle directory symlink",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeBundleDirectory, "bundleDirectory": map[string]any{"path": "bundle-link"}},
+			expectedErr: "path escapes from parent",
+		},
+		{
+			name:   "archive path",
+			source: map[string]any{"sourceType": specsv1.RegistryV1SourceTypeArchive, "archive": map[string]any{"path": "bundle.tar"}},
+		},
+		{
+			name:        "archive path outside the spec directory",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeArchive, "archive": map[string]any{"path": "../outside/bundle.tar"}},
+			expectedErr: `invalid source.archive.path "../outside/bundle.tar": must be a local path`,
+		},
+		{
+			name:        "archive path symlink",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeArchive, "archive": map[string]any{"path": "bundle-link.tar"}},
+			expectedErr: "path escapes from parent",
+		},
+		{
+			name:        "archive URL",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeArchive, "archive": map[string]any{"url": "https://example.com/bundle.tar", "sha256": "0000000000000000000000000000000000000000000000000000000000000000"}},
+			expectedErr: "source.archive.url is not supported",
+		},
+		{
+			name:   "git repository",
+			source: map[string]any{"sourceType": specsv1.RegistryV1SourceTypeGit, "git": map[string]any{"repository": "repo", "directory": "bundle"}},
+		},
+		{
+			name:        "git repository outside the spec directory",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeGit, "git": map[string]any{"repository": "../outside/repo", "directory": "bundle"}},
+			expectedErr: `invalid source.git.repository "../outside/repo": must be a local path`,
+		},
+		{
+			name:        "git directory symlink",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeGit, "git": map[string]any{"repository": "repo-link", "directory": "bundle"}},
+			expectedErr: `failed to open git repository`,
+		},
+		{
+			name:        "git file URL",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeGit, "git": map[string]any{"repository": "file://" + filepath.ToSlash(filepath.Join(outsideDir, "repo")), "directory": "bundle"}},
+			expectedErr: "source.git.repository URLs are not supported",
+		},
+		{
+			name:        "image",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeImage, "image": map[string]any{"reference": "oci-layout:../outside/layout"}},
+			expectedErr: `source type "Image" is not supported`,
+		},
+		{
+			name:        "kustomize",
+			source:      map[string]any{"sourceType": specsv1.RegistryV1SourceTypeKustomize, "kustomize": map[string]any{"directory": "bundle", "package": "example"}},
+			expectedErr: `source type "Kustomize" is not supported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Starlark{Registry: DefaultRegistry, Values: map[string]any{"spec": map[string]any{
+				"apiVersion": specsv1.GroupVersion.String(),
+				"kind":       specsv1.KindRegistryV1,
+				"source":     tt.source,
+			}}}
+			specs, err := l.LoadSpecFile(t.Context(), specFile)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, specs, 1)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package spec
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,13 +12,24 @@ import (
 
 type YAML struct {
 	Registry *Registry
+
+	// Template configures the rendering of spec files, which are Go
+	// templates that are rendered before they are decoded.
+	Template TemplateOptions
+
+	// Load configures how specs load their sources.
+	Load LoadOptions
 }
 
-func (l *YAML) LoadSpecFile(path string) (Spec, error) {
-	specFileData, err := os.ReadFile(path)
+func (l *YAML) LoadSpecFile(ctx context.Context, path string) (Spec, error) {
+	specFileTemplate, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	specFileData, err := RenderTemplate(filepath.Base(path), specFileTemplate, l.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render spec file %q: %w", path, err)
+	}
 
 	var obj metav1.PartialObjectMetadata
 	if err := yaml.Unmarshal(specFileData, &obj); err != nil {
@@ -30,7 +42,7 @@ func (l *YAML) LoadSpecFile(path string) (Spec, error) {
 		return nil, fmt.Errorf("failed to get spec loader from registry for GVK %q: %w", gvk, err)
 	}
 
-	return loadSpecFunc(specFileData, filepath.Dir(path))
+	return loadSpecFunc(ctx, specFileData, filepath.Dir(path), l.Load)
 }
 
 var DefaultYAML = &YAML{
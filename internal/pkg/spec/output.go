@@ -0,0 +1,136 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/registry"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+)
+
+const defaultOutputFile = "{{ .ID }}.kpm"
+
+// OutputOptions configure where a spec is built to and how its image is
+// tagged. File and Tags are templates over OutputFields.
+type OutputOptions struct {
+	// Directory is the directory that File is relative to.
+	Directory string
+	// File is the path of the kpm file. Relative paths are relative to
+	// Directory.
+	File string
+	// Tags are additional tags for the image. Each may render to several
+	// comma-separated tags.
+	Tags []string
+}
+
+// Merge returns o with the non-empty fields of overrides applied. Tags are
+// combined rather than replaced. When overrides sets File, it is no longer
+// relative to o.Directory.
+func (o OutputOptions) Merge(overrides OutputOptions) OutputOptions {
+	merged := o
+	if overrides.File != "" {
+		merged.File = overrides.File
+		merged.Directory = ""
+	}
+	if overrides.Directory != "" {
+		merged.Directory = overrides.Directory
+	}
+	merged.Tags = append(append([]string{}, o.Tags...), overrides.Tags...)
+	return merged
+}
+
+func newOutputOptions(output *specsv1.Output, workingDir string) OutputOptions {
+	if output == nil {
+		return OutputOptions{}
+	}
+	opts := OutputOptions{File: output.File, Tags: output.Tags}
+	if output.Directory != "" {
+		opts.Directory = filepath.Join(workingDir, output.Directory)
+	}
+	return opts
+}
+
+// OutputFields are the fields available to output templates.
+type OutputFields struct {
+	ID string
+	// Name is the name of the image, e.g. the package name of a bundle.
+	// Tags are applied as <Name>:<tag>.
+	Name     string
+	Package  string
+	Version  string
+	Channel  string
+	Channels []string
+	// Digest is the digest of the image manifest. Use .Digest.Encoded for
+	// only the hex-encoded part.
+	Digest digest.Digest
+}
+
+// OutputSpec is implemented by specs that provide default output options
+// and fields for output templates.
+type OutputSpec interface {
+	Spec
+	OutputOptions() OutputOptions
+	OutputFields() OutputFields
+}
+
+func (o OutputOptions) outputFile(fields OutputFields) (string, error) {
+	fileTemplate := o.File
+	if fileTemplate == "" {
+		fileTemplate = defaultOutputFile
+	}
+	file, err := renderOutputTemplate("file", fileTemplate, fields)
+	if err != nil {
+		return "", err
+	}
+	if file == "" {
+		return "", fmt.Errorf("output file template %q rendered an empty path", fileTemplate)
+	}
+	if filepath.IsAbs(file) || o.Directory == "" {
+		return file, nil
+	}
+	return filepath.Join(o.Directory, file), nil
+}
+
+func (o OutputOptions) tags(fields OutputFields) ([]string, error) {
+	var tags []string
+	for _, tagTemplate := range o.Tags {
+		rendered, err := renderOutputTemplate("tag", tagTemplate, fields)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range strings.Split(rendered, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			ref := registry.Reference{Registry: "localhost", Repository: "kpm", Reference: tag}
+			if err := ref.ValidateReferenceAsTag(); err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func renderOutputTemplate(name, text string, fields OutputFields) (string, error) {
+	funcs := sprig.TxtFuncMap()
+	for _, fn := range nondeterministicFuncs {
+		funcs[fn] = nondeterministicFunc(fn)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid output %s template %q: %v", name, text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("failed to render output %s template %q: %v", name, text, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,122 @@
+package spec
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type testOutputSpec struct {
+	testSpec
+	output OutputOptions
+}
+
+func (s testOutputSpec) OutputOptions() OutputOptions {
+	return s.output
+}
+
+func (s testOutputSpec) OutputFields() OutputFields {
+	return OutputFields{
+		ID:       s.id,
+		Name:     "example",
+		Package:  "example",
+		Version:  "1.2.3",
+		Channel:  "stable",
+		Channels: []string{"stable", "fast"},
+	}
+}
+
+func Test_Build_Output(t *testing.T) {
+	type testCase struct {
+		name             string
+		spec             Spec
+		opts             OutputOptions
+		expectFile       string
+		expectTags       []string
+		expectErrContain string
+	}
+	for _, tc := range []testCase{
+		{
+			name:       "default output file",
+			spec:       testSpec{id: "a"},
+			expectFile: "a.kpm",
+		},
+		{
+			name: "spec output options",
+			spec: testOutputSpec{testSpec: testSpec{id: "a"}, output: OutputOptions{
+				Directory: "out",
+				File:      "{{ .Package }}-{{ .Version }}.kpm",
+				Tags:      []string{"latest", `{{ join "," .Channels }}`},
+			}},
+			expectFile: filepath.Join("out", "example-1.2.3.kpm"),
+			expectTags: []string{"latest", "stable", "fast"},
+		},
+		{
+			name: "overrides",
+			spec: testOutputSpec{testSpec: testSpec{id: "a"}, output: OutputOptions{
+				Directory: "out",
+				File:      "{{ .Package }}-{{ .Version }}.kpm",
+				Tags:      []string{"latest"},
+			}},
+			opts:       OutputOptions{File: "{{ .Channel }}/{{ .Digest.Algorithm }}.kpm", Tags: []string{"{{ .Channel }}"}},
+			expectFile: filepath.Join("stable", "sha256.kpm"),
+			expectTags: []string{"latest", "stable"},
+		},
+		{
+			name: "override directory",
+			spec: testOutputSpec{testSpec: testSpec{id: "a"}, output: OutputOptions{
+				Directory: "out",
+				File:      "{{ .Package }}.kpm",
+			}},
+			opts:       OutputOptions{Directory: "other"},
+			expectFile: filepath.Join("other", "example.kpm"),
+		},
+		{
+			name:             "invalid tag",
+			spec:             testOutputSpec{testSpec: testSpec{id: "a"}, output: OutputOptions{Tags: []string{"{{ .Version }}+build"}}},
+			expectErrContain: `invalid tag "1.2.3+build"`,
+		},
+		{
+			name:             "tags without image name",
+			spec:             testSpec{id: "a"},
+			opts:             OutputOptions{Tags: []string{"latest"}},
+			expectErrContain: "cannot apply tags to a: image has no name",
+		},
+		{
+			name:             "unknown field",
+			spec:             testSpec{id: "a"},
+			opts:             OutputOptions{File: "{{ .Missing }}.kpm"},
+			expectErrContain: `failed to render output file template "{{ .Missing }}.kpm"`,
+		},
+		{
+			name:             "nondeterministic function",
+			spec:             testSpec{id: "a"},
+			opts:             OutputOptions{File: "{{ now }}.kpm"},
+			expectErrContain: `function "now" is nondeterministic`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+
+			report, err := Build(t.Context(), tc.spec, BuildOptions{Output: tc.opts})
+			if tc.expectErrContain != "" {
+				require.ErrorContains(t, err, tc.expectErrContain)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectFile, report.OutputFile)
+			require.Equal(t, tc.expectTags, report.Tags)
+
+			store, err := ocilayout.OpenArchive(t.Context(), report.OutputFile)
+			require.NoError(t, err)
+			tags, err := ocilayout.ManifestTags(t.Context(), store, report.Descriptor)
+			require.NoError(t, err)
+			for _, tag := range tc.expectTags {
+				require.Contains(t, tags, tag)
+			}
+		})
+	}
+}
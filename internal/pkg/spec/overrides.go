@@ -0,0 +1,79 @@
+package spec
+
+import (
+	"fmt"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+// metadataOverrides returns the metadata overrides of spec, and whether it
+// has any.
+func metadataOverrides(spec specsv1.RegistryV1) (registryv1.MetadataOverrides, bool, error) {
+	var overrides registryv1.MetadataOverrides
+	if spec.Annotations == nil && spec.Properties == nil && spec.Dependencies == nil {
+		return overrides, false, nil
+	}
+
+	if a := spec.Annotations; a != nil {
+		replace, err := overrideReplaces("annotations", a.Mode)
+		if err != nil {
+			return overrides, false, err
+		}
+		overrides.Annotations = a.Values
+		if overrides.Annotations == nil {
+			overrides.Annotations = map[string]string{}
+		}
+		overrides.ReplaceAnnotations = replace
+	}
+	if p := spec.Properties; p != nil {
+		replace, err := overrideReplaces("properties", p.Mode)
+		if err != nil {
+			return overrides, false, err
+		}
+		overrides.Properties = toProperties(p.Values)
+		overrides.ReplaceProperties = replace
+	}
+	if d := spec.Dependencies; d != nil {
+		replace, err := overrideReplaces("dependencies", d.Mode)
+		if err != nil {
+			return overrides, false, err
+		}
+		overrides.Dependencies = toProperties(d.Values)
+		overrides.ReplaceDependencies = replace
+	}
+	return overrides, true, nil
+}
+
+// overrideReplaces reports whether the override mode of the given section
+// replaces the corresponding metadata rather than merging into it.
+func overrideReplaces(section, mode string) (bool, error) {
+	switch mode {
+	case "", specsv1.RegistryV1OverrideModeMerge:
+		return false, nil
+	case specsv1.RegistryV1OverrideModeReplace:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown %s.mode %q: must be %q or %q", section, mode, specsv1.RegistryV1OverrideModeMerge, specsv1.RegistryV1OverrideModeReplace)
+	}
+}
+
+func toProperties(in []specsv1.RegistryV1Property) []registryv1.Property {
+	out := make([]registryv1.Property, 0, len(in))
+	for _, p := range in {
+		out = append(out, registryv1.Property{Type: p.Type, Value: p.Value})
+	}
+	return out
+}
+
+func toPatches(in []specsv1.RegistryV1Patch) []registryv1.Patch {
+	out := make([]registryv1.Patch, 0, len(in))
+	for _, p := range in {
+		out = append(out, registryv1.Patch{
+			Type:   p.Type,
+			Target: registryv1.PatchTarget{Group: p.Target.Group, Kind: p.Target.Kind, Name: p.Target.Name},
+			Patch:  []byte(p.Patch),
+		})
+	}
+	return out
+}
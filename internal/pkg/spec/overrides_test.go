@@ -0,0 +1,157 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadRegistryV1_MetadataOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, filepath.Join(dir, "bundle"))
+
+	tests := []struct {
+		name             string
+		overrides        string
+		expectedChannels []string
+		expectedErr      string
+	}{
+		{
+			name: "channels",
+			overrides: `
+annotations:
+  values:
+    operators.operatorframework.io.bundle.channels.v1: stable,fast
+    operators.operatorframework.io.bundle.channel.default.v1: stable
+properties:
+  values:
+    - type: olm.package.required
+      value:
+        packageName: other
+        versionRange: '>=1.0.0'
+`,
+			expectedChannels: []string{"stable", "fast"},
+		},
+		{
+			name: "unknown mode",
+			overrides: `
+dependencies:
+  mode: Append
+  values: []
+`,
+			expectedErr: `unknown dependencies.mode "Append": must be "Merge" or "Replace"`,
+		},
+		{
+			name: "invalid override",
+			overrides: `
+annotations:
+  mode: Replace
+  values:
+    operators.operatorframework.io.bundle.channels.v1: stable
+`,
+			expectedErr: `required key "operators.operatorframework.io.bundle.package.v1" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadRegistryV1Bytes(t.Context(), []byte(`
+apiVersion: specs.kpm.io/v1alpha1
+kind: RegistryV1
+source:
+  sourceType: BundleDirectory
+  bundleDirectory:
+    path: bundle
+`+tt.overrides), dir, LoadOptions{})
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			rv1 := s.(*registryV1Spec)
+			require.Equal(t, tt.expectedChannels, rv1.Summary().Channels)
+			require.NotNil(t, rv1.Metadata().Properties())
+		})
+	}
+}
+
+func Test_loadRegistryV1_Patches(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, filepath.Join(dir, "bundle"))
+
+	s, err := loadRegistryV1Bytes(t.Context(), []byte(`
+apiVersion: specs.kpm.io/v1alpha1
+kind: RegistryV1
+source:
+  sourceType: BundleDirectory
+  bundleDirectory:
+    path: bundle
+patches:
+  - type: StrategicMerge
+    target:
+      kind: ClusterServiceVersion
+    patch: |
+      metadata:
+        labels:
+          distribution: example
+  - type: JSON6902
+    target:
+      group: operators.coreos.com
+      name: example.v1.2.3
+    patch: |
+      - op: add
+        path: /spec/description
+        value: Patched description.
+`), dir, LoadOptions{})
+	require.NoError(t, err)
+	csv := s.(*registryV1Spec).Manifests().CSV()
+	require.Equal(t, map[string]string{"distribution": "example"}, csv.Value().GetLabels())
+	require.Equal(t, "Patched description.", csv.Value().Spec.Description)
+	require.Contains(t, string(csv.Data()), "description: Patched description.")
+}
+
+func Test_loadRegistryV1_PatchesRepairSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, filepath.Join(dir, "bundle"))
+	// The CRD is not owned by the CSV, so the source bundle is invalid.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bundle", "manifests", "crd.yaml"), []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resources.group.example.com
+spec:
+  names:
+    kind: Resource
+  versions:
+    - name: v1alpha1
+`), 0o644))
+	spec := `
+apiVersion: specs.kpm.io/v1alpha1
+kind: RegistryV1
+source:
+  sourceType: BundleDirectory
+  bundleDirectory:
+    path: bundle
+`
+	_, err := loadRegistryV1Bytes(t.Context(), []byte(spec), dir, LoadOptions{})
+	require.ErrorContains(t, err, "manifests/owned-apis")
+
+	s, err := loadRegistryV1Bytes(t.Context(), []byte(spec+`
+patches:
+  - type: JSON6902
+    target:
+      kind: ClusterServiceVersion
+    patch: |
+      - op: add
+        path: /spec/customresourcedefinitions
+        value:
+          owned:
+            - name: resources.group.example.com
+              version: v1alpha1
+              kind: Resource
+`), dir, LoadOptions{})
+	require.NoError(t, err)
+	csv := s.(*registryV1Spec).Manifests().CSV().Value()
+	require.Equal(t, "resources.group.example.com", csv.Spec.CustomResourceDefinitions.Owned[0].Name)
+}
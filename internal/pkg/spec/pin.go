@@ -0,0 +1,27 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+)
+
+// PinImagesSpec is a spec whose image references can be pinned to digests.
+type PinImagesSpec interface {
+	PinImages(context.Context, imageresolver.Resolver) ([]imageresolver.PinnedImage, error)
+}
+
+// pinImages pins the images referenced by spec to the digests resolver
+// resolves them to, before the spec is marshaled.
+func pinImages(ctx context.Context, spec Spec, resolver imageresolver.Resolver) ([]imageresolver.PinnedImage, error) {
+	pinSpec, ok := spec.(PinImagesSpec)
+	if !ok {
+		return nil, fmt.Errorf("spec %s does not support pinning images", spec.ID())
+	}
+	pinned, err := pinSpec.PinImages(ctx, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin images of %s: %w", spec.ID(), err)
+	}
+	return pinned, nil
+}
@@ -0,0 +1,46 @@
+package spec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+)
+
+type testPinSpec struct {
+	testLayerSpec
+}
+
+func (s testPinSpec) PinImages(ctx context.Context, resolver imageresolver.Resolver) ([]imageresolver.PinnedImage, error) {
+	pinned, err := imageresolver.Pin(ctx, resolver, "quay.io/example/image:v1")
+	if err != nil {
+		return nil, err
+	}
+	return []imageresolver.PinnedImage{{Original: "quay.io/example/image:v1", Pinned: pinned}}, nil
+}
+
+type testResolver struct{}
+
+func (testResolver) Resolve(_ context.Context, ref string) (digest.Digest, error) {
+	return digest.FromString(ref), nil
+}
+
+func Test_Build_PinImages(t *testing.T) {
+	t.Run("pinned images are reported", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		report, err := Build(t.Context(), testPinSpec{}, BuildOptions{ImageResolver: testResolver{}})
+		require.NoError(t, err)
+		require.Equal(t, []imageresolver.PinnedImage{{
+			Original: "quay.io/example/image:v1",
+			Pinned:   "quay.io/example/image@" + digest.FromString("quay.io/example/image:v1").String(),
+		}}, report.PinnedImages)
+	})
+	t.Run("unsupported spec", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		_, err := Build(t.Context(), testLayerSpec{}, BuildOptions{ImageResolver: testResolver{}})
+		require.ErrorContains(t, err, "spec layered does not support pinning images")
+	})
+}
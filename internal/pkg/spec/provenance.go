@@ -0,0 +1,165 @@
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/renameio/v2"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/version"
+)
+
+const (
+	InTotoStatementType  = "https://in-toto.io/Statement/v1"
+	SLSAProvenanceType   = "https://slsa.dev/provenance/v1"
+	InTotoMediaType      = "application/vnd.in-toto+json"
+	ProvenanceFileSuffix = ".provenance.json"
+
+	// ProvenanceBuildType identifies the kpm build process in provenance.
+	// The external parameters of such a build are the spec file and the ID
+	// of the spec built from it, and its resolved dependencies are the spec
+	// file and the source files of the spec, identified by their paths
+	// relative to the directory kpm was run in.
+	ProvenanceBuildType = "https://github.com/operator-framework/kpm/build/v1"
+	provenanceBuilderID = "https://github.com/operator-framework/kpm"
+
+	annotationInTotoPredicateType = "in-toto.io/predicate-type"
+)
+
+// ProvenanceOptions configure the build provenance of a kpm file.
+type ProvenanceOptions struct {
+	// Enabled writes the provenance next to the kpm file.
+	Enabled bool
+	// Attach attaches the provenance to the image in the kpm file as an OCI
+	// referrer. It implies Enabled.
+	Attach bool
+}
+
+// SourceSpec is implemented by specs built from files on disk.
+type SourceSpec interface {
+	Spec
+	// SourceFiles describes every file the spec is built from, with paths
+	// relative to the directory kpm was run in.
+	SourceFiles() ([]FileReport, error)
+}
+
+// InTotoStatement is an in-toto attestation statement.
+type InTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []InTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type InTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// SLSAProvenance is a SLSA provenance v1 predicate.
+type SLSAProvenance struct {
+	BuildDefinition SLSABuildDefinition `json:"buildDefinition"`
+	RunDetails      SLSARunDetails      `json:"runDetails"`
+}
+
+type SLSABuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]any           `json:"externalParameters"`
+	ResolvedDependencies []SLSAResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type SLSAResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type SLSARunDetails struct {
+	Builder  SLSABuilder       `json:"builder"`
+	Metadata SLSABuildMetadata `json:"metadata"`
+}
+
+type SLSABuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type SLSABuildMetadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// newProvenance returns an in-toto statement with a SLSA provenance
+// predicate whose subject is the image manifest described by desc.
+func newProvenance(spec Spec, name string, desc ocispec.Descriptor, specFile *FileReport, started time.Time) (*InTotoStatement, error) {
+	externalParameters := map[string]any{"id": spec.ID()}
+	var materials []FileReport
+	if specFile != nil {
+		externalParameters["specFile"] = specFile.Path
+		materials = append(materials, *specFile)
+	}
+	if sourceSpec, ok := spec.(SourceSpec); ok {
+		sourceFiles, err := sourceSpec.SourceFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe source files: %w", err)
+		}
+		materials = append(materials, sourceFiles...)
+	}
+	dependencies := make([]SLSAResourceDescriptor, 0, len(materials))
+	for _, material := range materials {
+		dependencies = append(dependencies, SLSAResourceDescriptor{
+			URI:    material.Path,
+			Digest: map[string]string{material.Digest.Algorithm().String(): material.Digest.Encoded()},
+		})
+	}
+
+	predicate, err := json.Marshal(SLSAProvenance{
+		BuildDefinition: SLSABuildDefinition{
+			BuildType:            ProvenanceBuildType,
+			ExternalParameters:   externalParameters,
+			ResolvedDependencies: dependencies,
+		},
+		RunDetails: SLSARunDetails{
+			Builder: SLSABuilder{
+				ID:      provenanceBuilderID,
+				Version: map[string]string{"kpm": version.Version()},
+			},
+			Metadata: SLSABuildMetadata{
+				StartedOn:  started.UTC(),
+				FinishedOn: time.Now().UTC(),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &InTotoStatement{
+		Type: InTotoStatementType,
+		Subject: []InTotoSubject{{
+			Name:   name,
+			Digest: map[string]string{desc.Digest.Algorithm().String(): desc.Digest.Encoded()},
+		}},
+		PredicateType: SLSAProvenanceType,
+		Predicate:     predicate,
+	}, nil
+}
+
+// attachProvenance attaches statement to the image described by subject.
+func attachProvenance(ctx context.Context, target oras.Target, subject ocispec.Descriptor, statement []byte) (ocispec.Descriptor, error) {
+	return ocilayout.Attach(ctx, target, subject, InTotoMediaType, InTotoMediaType, statement, map[string]string{
+		annotationInTotoPredicateType: SLSAProvenanceType,
+	})
+}
+
+func provenanceFile(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".kpm") + ProvenanceFileSuffix
+}
+
+func writeProvenance(path string, statement []byte) error {
+	return renameio.WriteFile(path, statement, 0644)
+}
@@ -0,0 +1,72 @@
+package spec
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type testSourceSpec struct {
+	testLayerSpec
+	sourceFiles []FileReport
+}
+
+func (s testSourceSpec) SourceFiles() ([]FileReport, error) {
+	return s.sourceFiles, nil
+}
+
+func Test_Build_Provenance(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("example.kpmspec.yaml", []byte("spec"), 0600))
+	specFile, err := NewFileReport("example.kpmspec.yaml")
+	require.NoError(t, err)
+
+	s := testSourceSpec{
+		testLayerSpec: testLayerSpec{files: fstest.MapFS{"manifests/a.yaml": {Data: []byte("a")}}},
+		sourceFiles:   []FileReport{{Path: "bundle/manifests/a.yaml", Size: 1, Digest: digest.FromString("a")}},
+	}
+	report, err := Build(t.Context(), s, BuildOptions{
+		SpecFile:   specFile,
+		Provenance: ProvenanceOptions{Attach: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "layered.provenance.json", report.ProvenanceFile)
+
+	data, err := os.ReadFile(report.ProvenanceFile)
+	require.NoError(t, err)
+	var statement InTotoStatement
+	require.NoError(t, json.Unmarshal(data, &statement))
+	require.Equal(t, InTotoStatementType, statement.Type)
+	require.Equal(t, SLSAProvenanceType, statement.PredicateType)
+	require.Equal(t, []InTotoSubject{{
+		Name:   "layered",
+		Digest: map[string]string{"sha256": report.Descriptor.Digest.Encoded()},
+	}}, statement.Subject)
+
+	var provenance SLSAProvenance
+	require.NoError(t, json.Unmarshal(statement.Predicate, &provenance))
+	require.Equal(t, ProvenanceBuildType, provenance.BuildDefinition.BuildType)
+	require.Equal(t, "example.kpmspec.yaml", provenance.BuildDefinition.ExternalParameters["specFile"])
+	require.Equal(t, []SLSAResourceDescriptor{
+		{URI: "example.kpmspec.yaml", Digest: map[string]string{"sha256": digest.FromString("spec").Encoded()}},
+		{URI: "bundle/manifests/a.yaml", Digest: map[string]string{"sha256": digest.FromString("a").Encoded()}},
+	}, provenance.BuildDefinition.ResolvedDependencies)
+
+	// The attached provenance is the same as the written one.
+	store, err := ocilayout.OpenArchive(t.Context(), filepath.Clean(report.OutputFile))
+	require.NoError(t, err)
+	referrers, err := ocilayout.Referrers(t.Context(), store, report.Descriptor, InTotoMediaType)
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	require.Equal(t, report.Referrers[0].Digest, referrers[0].Digest)
+	_, attached, err := ocilayout.FetchArtifact(t.Context(), store, referrers[0])
+	require.NoError(t, err)
+	require.Equal(t, data, attached)
+}
@@ -1,9 +1,13 @@
 package spec
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"testing/fstest"
 
 	"sigs.k8s.io/yaml"
 
@@ -17,20 +21,251 @@ func init() {
 	}
 }
 
-func loadRegistryV1Bytes(specData []byte, workingDir string) (Spec, error) {
+func loadRegistryV1Bytes(ctx context.Context, specData []byte, workingDir string, opts LoadOptions) (Spec, error) {
 	var rv1Spec specsv1.RegistryV1
 	if err := yaml.Unmarshal(specData, &rv1Spec); err != nil {
 		return nil, err
 	}
-	return loadRegistryV1(rv1Spec, workingDir)
+	return loadRegistryV1(ctx, rv1Spec, workingDir, opts)
 }
 
-func loadRegistryV1(spec specsv1.RegistryV1, workingDir string) (Spec, error) {
+func loadRegistryV1(ctx context.Context, spec specsv1.RegistryV1, workingDir string, opts LoadOptions) (Spec, error) {
+	var loadOpts registryv1.LoadOptions
+	overrides, ok, err := metadataOverrides(spec)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		loadOpts.MetadataOverrides = &overrides
+	}
+	loadOpts.Patches = toPatches(spec.Patches)
+
+	var src registryV1Source
 	switch spec.Source.SourceType {
 	case specsv1.RegistryV1SourceTypeBundleDirectory:
-		l := registryv1.NewBundleFSLoader(os.DirFS(filepath.Join(workingDir, spec.Source.BundleDirectory.Path)))
-		return l.Load()
+		if spec.Source.BundleDirectory == nil {
+			return nil, fmt.Errorf("source type %q requires source.bundleDirectory", spec.Source.SourceType)
+		}
+		if opts.Root != nil {
+			src, err = loadConfinedBundleDirectorySource(opts.Root, spec.Source.BundleDirectory.Path, workingDir)
+			break
+		}
+		src, err = loadBundleDirectorySource(filepath.Join(workingDir, spec.Source.BundleDirectory.Path))
+	case specsv1.RegistryV1SourceTypeImage:
+		if spec.Source.Image == nil {
+			return nil, fmt.Errorf("source type %q requires source.image", spec.Source.SourceType)
+		}
+		if opts.Root != nil {
+			return nil, errConfinedSourceType(spec.Source.SourceType)
+		}
+		src, err = loadImageSource(ctx, spec.Source.Image.Reference, workingDir, opts.PlainHTTP)
+	case specsv1.RegistryV1SourceTypeArchive:
+		if spec.Source.Archive == nil {
+			return nil, fmt.Errorf("source type %q requires source.archive", spec.Source.SourceType)
+		}
+		src, err = loadArchiveSource(ctx, *spec.Source.Archive, workingDir, opts.Root)
+	case specsv1.RegistryV1SourceTypeGit:
+		if spec.Source.Git == nil {
+			return nil, fmt.Errorf("source type %q requires source.git", spec.Source.SourceType)
+		}
+		src, err = loadGitSource(*spec.Source.Git, workingDir, opts.Root)
+	case specsv1.RegistryV1SourceTypeKustomize:
+		if spec.Source.Kustomize == nil {
+			return nil, fmt.Errorf("source type %q requires source.kustomize", spec.Source.SourceType)
+		}
+		// Kustomizations can refer to remote bases and resources.
+		if opts.Root != nil {
+			return nil, errConfinedSourceType(spec.Source.SourceType)
+		}
+		src, err = loadKustomizeSource(*spec.Source.Kustomize, workingDir)
 	default:
 		return nil, fmt.Errorf("unknown source type: %q", spec.Source.SourceType)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The bundle is only validated once the spec's transformations are
+	// applied, so that they can fix an invalid source.
+	b, err := registryv1.NewBundleFSLoaderWithOptions(src.fsys, loadOpts).Load()
+	if err != nil {
+		if src.location != "" {
+			return nil, fmt.Errorf("%s: %w", src.location, err)
+		}
+		return nil, err
+	}
+	b.SetImageAnnotations(src.imageAnnotations)
+
+	s := &registryV1Spec{
+		Bundle:         b,
+		output:         newOutputOptions(spec.Output, workingDir),
+		sourceFiles:    src.sourceFiles,
+		sourceRevision: src.revision,
+	}
+	if spec.PopulateRelatedImages {
+		if s.warnings, err = s.PopulateRelatedImages(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// registryV1Source is the bundle directory read from the source of a spec,
+// along with a function that describes the files it was read from. The
+// bundle is loaded, and validated, by loadRegistryV1.
+type registryV1Source struct {
+	fsys        fs.FS
+	sourceFiles func() ([]FileReport, error)
+	// location describes the source in errors from loading the bundle, if
+	// set.
+	location string
+	// revision is the version control revision the bundle was read from,
+	// if any.
+	revision *SourceRevision
+	// imageAnnotations are added to the image manifest of the bundle.
+	imageAnnotations map[string]string
+}
+
+func loadBundleDirectorySource(bundleDir string) (registryV1Source, error) {
+	return registryV1Source{fsys: os.DirFS(bundleDir), sourceFiles: func() ([]FileReport, error) {
+		return directoryFiles(bundleDir)
+	}}, nil
+}
+
+// loadConfinedBundleDirectorySource reads the bundle directory at the path
+// bundleDir, relative to workingDir, through root. Its files are described
+// up front, since root is only open while the spec is loaded.
+func loadConfinedBundleDirectorySource(root *os.Root, bundleDir string, workingDir string) (registryV1Source, error) {
+	name, err := confinedPath(root, "source.bundleDirectory.path", bundleDir)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	fsys, err := fs.Sub(root.FS(), name)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	// Read the bundle into memory, so that it is built from the same files
+	// that are described in the build report.
+	dir := filepath.Join(workingDir, bundleDir)
+	data := fstest.MapFS{}
+	var files []FileReport
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := fs.Stat(fsys, path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		data[path] = &fstest.MapFile{Data: b, Mode: info.Mode().Perm()}
+		file, err := newFileReport(filepath.ToSlash(filepath.Join(dir, path)), bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		files = append(files, *file)
+		return nil
+	}); err != nil {
+		return registryV1Source{}, err
+	}
+	return registryV1Source{fsys: data, sourceFiles: func() ([]FileReport, error) {
+		return files, nil
+	}}, nil
+}
+
+// confinedPath checks that the source path p, relative to the directory of
+// root, is local to it and does not escape it through symlinks, and returns
+// it as an io/fs path. field names the spec field of p in errors.
+func confinedPath(root *os.Root, field string, p string) (string, error) {
+	if !filepath.IsLocal(p) {
+		return "", fmt.Errorf("invalid %s %q: must be a local path within the spec file's directory", field, p)
+	}
+	if _, err := root.Stat(p); err != nil {
+		return "", fmt.Errorf("invalid %s %q: %w", field, p, err)
+	}
+	return filepath.ToSlash(filepath.Clean(p)), nil
+}
+
+func errConfinedSourceType(sourceType string) error {
+	return fmt.Errorf("source type %q is not supported in specs confined to the spec file's directory", sourceType)
+}
+
+// registryV1Spec is a registry+v1 bundle along with the output options of
+// the spec that defined it.
+type registryV1Spec struct {
+	*registryv1.Bundle
+	output         OutputOptions
+	sourceFiles    func() ([]FileReport, error)
+	sourceRevision *SourceRevision
+	// warnings are the warnings from transforming the bundle.
+	warnings []string
+}
+
+func (s *registryV1Spec) Warnings() []string {
+	return s.warnings
+}
+
+func (s *registryV1Spec) SourceRevision() *SourceRevision {
+	return s.sourceRevision
+}
+
+func (s *registryV1Spec) SourceFiles() ([]FileReport, error) {
+	if s.sourceFiles == nil {
+		return nil, nil
+	}
+	return s.sourceFiles()
+}
+
+// directoryFiles describes every regular file in dir.
+func directoryFiles(dir string) ([]FileReport, error) {
+	fsys := os.DirFS(dir)
+	var files []FileReport
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := fs.Stat(fsys, path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := NewFileReport(filepath.Join(dir, path))
+		if err != nil {
+			return err
+		}
+		file.Path = filepath.ToSlash(file.Path)
+		files = append(files, *file)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (s *registryV1Spec) OutputOptions() OutputOptions {
+	return s.output
+}
+
+func (s *registryV1Spec) OutputFields() OutputFields {
+	summary := s.Summary()
+	channel := summary.DefaultChannel
+	if channel == "" && len(summary.Channels) > 0 {
+		channel = summary.Channels[0]
+	}
+	return OutputFields{
+		ID:       summary.ID,
+		Name:     summary.Package,
+		Package:  summary.Package,
+		Version:  summary.Version,
+		Channel:  channel,
+		Channels: summary.Channels,
+	}
 }
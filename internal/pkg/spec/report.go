@@ -0,0 +1,220 @@
+package spec
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/renameio/v2"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2/content"
+	"sigs.k8s.io/yaml"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	"github.com/operator-framework/kpm/internal/pkg/imageresolver"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+const (
+	KindBuildReport     = "BuildReport"
+	KindBuildReportList = "BuildReportList"
+
+	ReportFormatJSON = "json"
+	ReportFormatYAML = "yaml"
+)
+
+// BuildReport describes a built kpm file: the image it contains, the files
+// in that image, and how it was built.
+type BuildReport struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ID string `json:"id"`
+	// SpecFile is the spec file that defined the spec, if known.
+	SpecFile   *FileReport `json:"specFile,omitempty"`
+	KPMVersion string      `json:"kpmVersion"`
+
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	Config     ocispec.Descriptor `json:"config"`
+	Layers     []LayerReport      `json:"layers"`
+	OutputFile string             `json:"outputFile"`
+	Tags       []string           `json:"tags,omitempty"`
+	// Referrers are the artifacts attached to the image, such as its
+	// provenance and SBOMs.
+	Referrers      []ocispec.Descriptor `json:"referrers,omitempty"`
+	ProvenanceFile string               `json:"provenanceFile,omitempty"`
+	SBOMFiles      []string             `json:"sbomFiles,omitempty"`
+	// PinnedImages are the image references that were pinned to digests.
+	PinnedImages []imageresolver.PinnedImage `json:"pinnedImages,omitempty"`
+	// Warnings are the warnings produced while loading the spec.
+	Warnings []string `json:"warnings,omitempty"`
+	// SourceRevision is the version control revision the spec was built
+	// from, if any.
+	SourceRevision *SourceRevision `json:"sourceRevision,omitempty"`
+
+	Timings BuildTimings `json:"timings"`
+}
+
+// SourceRevision identifies the commit of a version control repository that
+// a spec was built from.
+type SourceRevision struct {
+	// Repository is the URL of the repository.
+	Repository string `json:"repository"`
+	// Revision is the revision as given in the spec, e.g. a tag.
+	Revision string `json:"revision,omitempty"`
+	// Commit is the commit the revision resolved to.
+	Commit string `json:"commit"`
+}
+
+// LayerReport describes an image layer and, for tar layers, the regular
+// files it contains.
+type LayerReport struct {
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	Files      []FileReport       `json:"files,omitempty"`
+}
+
+// FileReport describes a file by its path, size, and sha256 digest.
+type FileReport struct {
+	Path   string        `json:"path"`
+	Size   int64         `json:"size"`
+	Digest digest.Digest `json:"digest"`
+}
+
+// BuildTimings record when a build started and how long each of its phases
+// took.
+type BuildTimings struct {
+	Started metav1.Time `json:"started"`
+	// Marshal is the time taken to marshal the spec into an image.
+	Marshal metav1.Duration `json:"marshal"`
+	// Write is the time taken to write the kpm file.
+	Write metav1.Duration `json:"write"`
+	Total metav1.Duration `json:"total"`
+}
+
+func newBuildReport() *BuildReport {
+	return &BuildReport{TypeMeta: metav1.TypeMeta{
+		APIVersion: specsv1.GroupVersion.String(),
+		Kind:       KindBuildReport,
+	}}
+}
+
+// WriteFile writes the report to reportFile in the given format (json or
+// yaml). If reportFile is "-", the report is written to stdout.
+func (r BuildReport) WriteFile(reportFile string, format string) error {
+	return writeReport(reportFile, format, r)
+}
+
+// NewFileReport describes the file at path.
+func NewFileReport(path string) (*FileReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newFileReport(path, f)
+}
+
+func newFileReport(path string, r io.Reader) (*FileReport, error) {
+	digester := digest.SHA256.Digester()
+	size, err := io.Copy(digester.Hash(), r)
+	if err != nil {
+		return nil, err
+	}
+	return &FileReport{Path: path, Size: size, Digest: digester.Digest()}, nil
+}
+
+// describeImage fills in the config and layers of the image manifest
+// described by r.Descriptor.
+func (r *BuildReport) describeImage(ctx context.Context, fetcher content.Fetcher) error {
+	manifest, err := ocilayout.FetchManifest(ctx, fetcher, r.Descriptor)
+	if err != nil {
+		return err
+	}
+	r.Config = manifest.Config
+	r.Layers = make([]LayerReport, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		if !ocilayout.IsTarLayer(layer) {
+			r.Layers = append(r.Layers, LayerReport{Descriptor: layer})
+			continue
+		}
+		files, err := layerFiles(ctx, fetcher, layer)
+		if err != nil {
+			return fmt.Errorf("failed to list files of layer %s: %w", layer.Digest, err)
+		}
+		r.Layers = append(r.Layers, LayerReport{Descriptor: layer, Files: files})
+	}
+	return nil
+}
+
+func layerFiles(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]FileReport, error) {
+	layer, err := ocilayout.OpenLayer(ctx, fetcher, desc)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileReport
+	tr := tar.NewReader(layer)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		file, err := newFileReport(path.Clean(strings.TrimPrefix(header.Name, "/")), tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+	}
+}
+
+func writeReport(reportFile string, format string, report any) error {
+	if reportFile == "-" {
+		return encodeReport(os.Stdout, format, report)
+	}
+	reportData, err := marshalReport(format, report)
+	if err != nil {
+		return err
+	}
+	return renameio.WriteFile(reportFile, reportData, 0644)
+}
+
+func encodeReport(w io.Writer, format string, report any) error {
+	reportData, err := marshalReport(format, report)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(reportData)
+	return err
+}
+
+func marshalReport(format string, report any) ([]byte, error) {
+	var (
+		reportData []byte
+		err        error
+	)
+	switch format {
+	case ReportFormatJSON, "":
+		reportData, err = json.MarshalIndent(report, "", "  ")
+		reportData = append(reportData, '\n')
+	case ReportFormatYAML:
+		reportData, err = yaml.Marshal(report)
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %v", err)
+	}
+	return reportData, nil
+}
@@ -0,0 +1,93 @@
+package spec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/tar"
+)
+
+type testLayerSpec struct {
+	files fstest.MapFS
+}
+
+func (s testLayerSpec) ID() string {
+	return "layered"
+}
+
+func (s testLayerSpec) MarshalOCI(ctx context.Context, target oras.Target) (ocispec.Descriptor, error) {
+	var layerData bytes.Buffer
+	gzipWriter := gzip.NewWriter(&layerData)
+	if err := tar.Directory(gzipWriter, s.files); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	layer, err := oras.PushBytes(ctx, target, ocispec.MediaTypeImageLayerGzip, layerData.Bytes())
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, "application/vnd.example.test", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, target.Tag(ctx, desc, "layered:latest")
+}
+
+func Test_Build_Report(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	report, err := Build(t.Context(), testLayerSpec{files: fstest.MapFS{
+		"manifests/a.yaml":          {Data: []byte("a")},
+		"metadata/annotations.yaml": {Data: []byte("annotations")},
+	}}, BuildOptions{})
+	require.NoError(t, err)
+
+	require.Equal(t, "specs.kpm.io/v1alpha1", report.APIVersion)
+	require.Equal(t, KindBuildReport, report.Kind)
+	require.NotEmpty(t, report.KPMVersion)
+	require.Equal(t, ocispec.MediaTypeEmptyJSON, report.Config.MediaType)
+	require.Len(t, report.Layers, 1)
+	require.Equal(t, []FileReport{
+		{Path: "manifests/a.yaml", Size: 1, Digest: digest.FromString("a")},
+		{Path: "metadata/annotations.yaml", Size: 11, Digest: digest.FromString("annotations")},
+	}, report.Layers[0].Files)
+	require.False(t, report.Timings.Started.IsZero())
+	require.Equal(t, report.Timings.Total.Duration, report.Timings.Marshal.Duration+report.Timings.Write.Duration)
+}
+
+func Test_BuildReport_WriteFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	report := newBuildReport()
+	report.ID = "example.v1.2.3"
+
+	t.Run("yaml", func(t *testing.T) {
+		require.NoError(t, report.WriteFile("report.yaml", ReportFormatYAML))
+		data, err := os.ReadFile("report.yaml")
+		require.NoError(t, err)
+		var actual BuildReport
+		require.NoError(t, yaml.UnmarshalStrict(data, &actual))
+		require.Equal(t, KindBuildReport, actual.Kind)
+		require.Equal(t, report.ID, actual.ID)
+	})
+	t.Run("stdout", func(t *testing.T) {
+		require.NoError(t, report.WriteFile("-", ReportFormatJSON))
+		require.NoFileExists(t, "-")
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		require.ErrorContains(t, report.WriteFile("report.toml", "toml"), `unknown report format "toml"`)
+	})
+}
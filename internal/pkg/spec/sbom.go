@@ -0,0 +1,87 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/renameio/v2"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/version"
+)
+
+// SBOMOptions configure the SBOMs of a kpm file.
+type SBOMOptions struct {
+	// Formats are the SBOM formats to write next to the kpm file.
+	Formats []string
+	// Attach attaches the SBOMs to the image in the kpm file as OCI
+	// referrers. If no formats are set, an SPDX SBOM is attached.
+	Attach bool
+}
+
+func (o SBOMOptions) formats() []string {
+	if len(o.Formats) == 0 && o.Attach {
+		return []string{sbom.FormatSPDX}
+	}
+	return o.Formats
+}
+
+// SBOMSpec is implemented by specs that can describe their content in an
+// SBOM.
+type SBOMSpec interface {
+	Spec
+	Inventory() (sbom.Inventory, error)
+}
+
+var sbomFileSuffixes = map[string]string{
+	sbom.FormatSPDX:      ".spdx.json",
+	sbom.FormatCycloneDX: ".cdx.json",
+}
+
+type generatedSBOM struct {
+	format string
+	data   []byte
+}
+
+func generateSBOMs(spec Spec, formats []string, created time.Time) ([]generatedSBOM, error) {
+	sbomSpec, ok := spec.(SBOMSpec)
+	if !ok {
+		return nil, fmt.Errorf("spec %s does not support SBOMs", spec.ID())
+	}
+	inv, err := sbomSpec.Inventory()
+	if err != nil {
+		return nil, err
+	}
+	sboms := make([]generatedSBOM, 0, len(formats))
+	for _, format := range formats {
+		data, err := sbom.Generate(format, inv, sbom.Options{Created: created, ToolVersion: version.Version()})
+		if err != nil {
+			return nil, err
+		}
+		sboms = append(sboms, generatedSBOM{format: format, data: data})
+	}
+	return sboms, nil
+}
+
+// attachSBOM attaches s to the image described by subject. Its artifact
+// type is the media type of its format.
+func attachSBOM(ctx context.Context, target oras.Target, subject ocispec.Descriptor, s generatedSBOM) (ocispec.Descriptor, error) {
+	mediaType, err := sbom.MediaType(s.format)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return ocilayout.Attach(ctx, target, subject, mediaType, mediaType, s.data, nil)
+}
+
+func sbomFile(outputFile string, format string) string {
+	return strings.TrimSuffix(outputFile, ".kpm") + sbomFileSuffixes[format]
+}
+
+func writeSBOM(path string, data []byte) error {
+	return renameio.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,61 @@
+package spec
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/kpm/internal/pkg/sbom"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+type testSBOMSpec struct {
+	testLayerSpec
+}
+
+func (s testSBOMSpec) Inventory() (sbom.Inventory, error) {
+	return sbom.Inventory{Name: "layered", Version: "1.0.0"}, nil
+}
+
+func Test_Build_SBOM(t *testing.T) {
+	t.Run("write and attach", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		report, err := Build(t.Context(), testSBOMSpec{}, BuildOptions{SBOM: SBOMOptions{
+			Formats: []string{sbom.FormatSPDX, sbom.FormatCycloneDX},
+			Attach:  true,
+		}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"layered.spdx.json", "layered.cdx.json"}, report.SBOMFiles)
+
+		store, err := ocilayout.OpenArchive(t.Context(), report.OutputFile)
+		require.NoError(t, err)
+		for _, file := range report.SBOMFiles {
+			written, err := os.ReadFile(file)
+			require.NoError(t, err)
+			mediaType := sbom.MediaTypeSPDX
+			if file == "layered.cdx.json" {
+				mediaType = sbom.MediaTypeCycloneDX
+			}
+			referrers, err := ocilayout.Referrers(t.Context(), store, report.Descriptor, mediaType)
+			require.NoError(t, err)
+			require.Len(t, referrers, 1)
+			_, attached, err := ocilayout.FetchArtifact(t.Context(), store, referrers[0])
+			require.NoError(t, err)
+			require.Equal(t, written, attached)
+		}
+	})
+	t.Run("attach defaults to SPDX", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		report, err := Build(t.Context(), testSBOMSpec{}, BuildOptions{SBOM: SBOMOptions{Attach: true}})
+		require.NoError(t, err)
+		require.Empty(t, report.SBOMFiles)
+		require.Len(t, report.Referrers, 1)
+		require.Equal(t, sbom.MediaTypeSPDX, report.Referrers[0].ArtifactType)
+	})
+	t.Run("unsupported spec", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		_, err := Build(t.Context(), testLayerSpec{}, BuildOptions{SBOM: SBOMOptions{Formats: []string{sbom.FormatSPDX}}})
+		require.ErrorContains(t, err, "spec layered does not support SBOMs")
+	})
+}
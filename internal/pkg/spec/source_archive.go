@@ -0,0 +1,137 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	"github.com/operator-framework/kpm/internal/pkg/util/archive"
+)
+
+// loadArchiveSource reads the bundle directory in the archive described by
+// src. The archive is read into memory and verified against its checksum
+// before its contents are parsed; it is never extracted to disk. A relative
+// archive path is relative to workingDir. If root is set, the archive path
+// is read through root, and archive URLs are rejected (see LoadOptions.Root).
+func loadArchiveSource(ctx context.Context, src specsv1.RegistryV1ArchiveSource, workingDir string, root *os.Root) (registryV1Source, error) {
+	var expected digest.Digest
+	if src.SHA256 != "" {
+		expected = digest.NewDigestFromEncoded(digest.SHA256, src.SHA256)
+		if err := expected.Validate(); err != nil {
+			return registryV1Source{}, fmt.Errorf("invalid source.archive.sha256 %q: %w", src.SHA256, err)
+		}
+	}
+
+	var (
+		location string
+		data     []byte
+		err      error
+	)
+	switch {
+	case src.Path != "" && src.URL != "":
+		return registryV1Source{}, fmt.Errorf("source.archive.path and source.archive.url are mutually exclusive")
+	case src.Path != "" && root != nil:
+		name, err := confinedPath(root, "source.archive.path", src.Path)
+		if err != nil {
+			return registryV1Source{}, err
+		}
+		location = filepath.Join(workingDir, src.Path)
+		if data, err = fs.ReadFile(root.FS(), name); err != nil {
+			return registryV1Source{}, err
+		}
+	case src.Path != "":
+		location = filepath.Join(workingDir, src.Path)
+		if data, err = os.ReadFile(location); err != nil {
+			return registryV1Source{}, err
+		}
+	case src.URL != "" && root != nil:
+		return registryV1Source{}, fmt.Errorf("source.archive.url is not supported in specs confined to the spec file's directory")
+	case src.URL != "":
+		if expected == "" {
+			return registryV1Source{}, fmt.Errorf("source.archive.sha256 is required for archive URLs")
+		}
+		location = src.URL
+		if data, err = download(ctx, src.URL); err != nil {
+			return registryV1Source{}, err
+		}
+	default:
+		return registryV1Source{}, fmt.Errorf("source.archive requires a path or url")
+	}
+
+	actual := digest.SHA256.FromBytes(data)
+	if expected != "" && actual != expected {
+		return registryV1Source{}, fmt.Errorf("%s: checksum mismatch: expected sha256 %s, got %s", location, expected.Encoded(), actual.Encoded())
+	}
+
+	fsys, err := archive.FS(data)
+	if err != nil {
+		return registryV1Source{}, fmt.Errorf("failed to read archive %s: %w", location, err)
+	}
+	if src.Directory != "" {
+		dir := path.Clean(src.Directory)
+		if !fs.ValidPath(dir) {
+			return registryV1Source{}, fmt.Errorf("invalid source.archive.directory %q", src.Directory)
+		}
+		if fsys, err = fs.Sub(fsys, dir); err != nil {
+			return registryV1Source{}, err
+		}
+	}
+
+	file := FileReport{Path: filepath.ToSlash(location), Size: int64(len(data)), Digest: actual}
+	return registryV1Source{fsys: fsys, location: location, sourceFiles: func() ([]FileReport, error) {
+		return []FileReport{file}, nil
+	}}, nil
+}
+
+// archiveHTTPClient downloads archive URLs. Its timeout bounds the whole
+// download, so that an unresponsive server cannot stall a build.
+var archiveHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+// maxArchiveSize is the maximum size of a downloaded archive, which is read
+// into memory.
+var maxArchiveSize int64 = 512 << 20
+
+// download returns the contents of the HTTP(S) URL rawURL, which must not be
+// larger than maxArchiveSize.
+func download(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported archive URL scheme %q", u.Scheme)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := archiveHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+	if resp.ContentLength > maxArchiveSize {
+		return nil, fmt.Errorf("failed to download %s: archive is larger than %d bytes", rawURL, maxArchiveSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	if int64(len(data)) > maxArchiveSize {
+		return nil, fmt.Errorf("failed to download %s: archive is larger than %d bytes", rawURL, maxArchiveSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,160 @@
+package spec
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	tarutil "github.com/operator-framework/kpm/internal/pkg/util/tar"
+)
+
+func Test_loadArchiveSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, filepath.Join(dir, "release", "bundle"))
+	fsys := os.DirFS(filepath.Join(dir, "release"))
+
+	var tarData bytes.Buffer
+	require.NoError(t, tarutil.Directory(&tarData, fsys))
+
+	var tgzData bytes.Buffer
+	gw := gzip.NewWriter(&tgzData)
+	_, err := gw.Write(tarData.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	require.NoError(t, zw.AddFS(fsys))
+	require.NoError(t, zw.Close())
+
+	for name, data := range map[string][]byte{
+		"bundle.tar":    tarData.Bytes(),
+		"bundle.tar.gz": tgzData.Bytes(),
+		"bundle.zip":    zipData.Bytes(),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	tgzDigest := digest.FromBytes(tgzData.Bytes())
+	tests := []struct {
+		name         string
+		source       specsv1.RegistryV1ArchiveSource
+		expectedFile FileReport
+		expectedErr  string
+	}{
+		{
+			name:         "tar",
+			source:       specsv1.RegistryV1ArchiveSource{Path: "bundle.tar", Directory: "bundle"},
+			expectedFile: FileReport{Path: filepath.ToSlash(filepath.Join(dir, "bundle.tar")), Size: int64(tarData.Len()), Digest: digest.FromBytes(tarData.Bytes())},
+		},
+		{
+			name:         "tar.gz with checksum",
+			source:       specsv1.RegistryV1ArchiveSource{Path: "bundle.tar.gz", SHA256: tgzDigest.Encoded(), Directory: "bundle"},
+			expectedFile: FileReport{Path: filepath.ToSlash(filepath.Join(dir, "bundle.tar.gz")), Size: int64(tgzData.Len()), Digest: tgzDigest},
+		},
+		{
+			name:         "zip",
+			source:       specsv1.RegistryV1ArchiveSource{Path: "bundle.zip", Directory: "bundle/"},
+			expectedFile: FileReport{Path: filepath.ToSlash(filepath.Join(dir, "bundle.zip")), Size: int64(zipData.Len()), Digest: digest.FromBytes(zipData.Bytes())},
+		},
+		{
+			name:         "url",
+			source:       specsv1.RegistryV1ArchiveSource{URL: srv.URL + "/bundle.tar.gz", SHA256: tgzDigest.Encoded(), Directory: "bundle"},
+			expectedFile: FileReport{Path: srv.URL + "/bundle.tar.gz", Size: int64(tgzData.Len()), Digest: tgzDigest},
+		},
+		{
+			name:        "url without checksum",
+			source:      specsv1.RegistryV1ArchiveSource{URL: srv.URL + "/bundle.tar.gz", Directory: "bundle"},
+			expectedErr: "source.archive.sha256 is required for archive URLs",
+		},
+		{
+			name:        "url not found",
+			source:      specsv1.RegistryV1ArchiveSource{URL: srv.URL + "/missing.tar.gz", SHA256: tgzDigest.Encoded()},
+			expectedErr: "404 Not Found",
+		},
+		{
+			name:        "checksum mismatch",
+			source:      specsv1.RegistryV1ArchiveSource{Path: "bundle.tar", SHA256: tgzDigest.Encoded(), Directory: "bundle"},
+			expectedErr: "checksum mismatch",
+		},
+		{
+			name:        "invalid checksum",
+			source:      specsv1.RegistryV1ArchiveSource{Path: "bundle.tar", SHA256: "abc"},
+			expectedErr: `invalid source.archive.sha256 "abc"`,
+		},
+		{
+			name:        "directory escapes archive",
+			source:      specsv1.RegistryV1ArchiveSource{Path: "bundle.tar", Directory: "../bundle"},
+			expectedErr: `invalid source.archive.directory "../bundle"`,
+		},
+		{
+			name:        "path and url",
+			source:      specsv1.RegistryV1ArchiveSource{Path: "bundle.tar", URL: srv.URL + "/bundle.tar"},
+			expectedErr: "mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{Source: specsv1.RegistryV1Source{
+				SourceType: specsv1.RegistryV1SourceTypeArchive,
+				Archive:    &tt.source,
+			}}, dir, LoadOptions{})
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "example.v1.2.3", s.ID())
+
+			files, err := s.(SourceSpec).SourceFiles()
+			require.NoError(t, err)
+			require.Equal(t, []FileReport{tt.expectedFile}, files)
+		})
+	}
+}
+
+func Test_loadArchiveSource_UnsupportedEntry(t *testing.T) {
+	var data bytes.Buffer
+	tw := tar.NewWriter(&data)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "manifests", Typeflag: tar.TypeSymlink, Linkname: "/etc"}))
+	require.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bundle.tar"), data.Bytes(), 0o644))
+	_, err := loadArchiveSource(t.Context(), specsv1.RegistryV1ArchiveSource{Path: "bundle.tar"}, dir, nil)
+	require.ErrorContains(t, err, `unsupported entry type "2" for "manifests"`)
+}
+
+func Test_download_MaxArchiveSize(t *testing.T) {
+	defer func(size int64) { maxArchiveSize = size }(maxArchiveSize)
+	maxArchiveSize = 4
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// flushing before writing the body omits the Content-Length header
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte("12345"))
+	}))
+	defer srv.Close()
+
+	for _, name := range []string{"content-length", "chunked"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := download(t.Context(), srv.URL+"/"+name)
+			require.ErrorContains(t, err, "archive is larger than 4 bytes")
+		})
+	}
+}
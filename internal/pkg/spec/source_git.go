@@ -0,0 +1,240 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+)
+
+const fileURLPrefix = "file://"
+
+// loadGitSource reads the bundle directory in the commit that src.Revision
+// resolves to. The bundle is read from the repository's object database, so
+// the working tree of the repository is neither read nor modified. A
+// relative repository path is relative to workingDir. If root is set, the
+// repository must be a local path within root (see LoadOptions.Root).
+func loadGitSource(src specsv1.RegistryV1GitSource, workingDir string, root *os.Root) (registryV1Source, error) {
+	var (
+		repoPath string
+		repo     *git.Repository
+		err      error
+	)
+	if root != nil {
+		repoPath, repo, err = openConfinedGitRepository(root, src.Repository, workingDir)
+	} else {
+		repoPath, repo, err = openGitRepository(src.Repository, workingDir)
+	}
+	if err != nil {
+		return registryV1Source{}, err
+	}
+
+	revision := src.Revision
+	if revision == "" {
+		revision = plumbing.HEAD.String()
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return registryV1Source{}, fmt.Errorf("failed to resolve revision %q of %s: %w", revision, src.Repository, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	dir := "."
+	if src.Directory != "" {
+		dir = path.Clean(src.Directory)
+		if !fs.ValidPath(dir) {
+			return registryV1Source{}, fmt.Errorf("invalid source.git.directory %q", src.Directory)
+		}
+		if tree, err = tree.Tree(dir); err != nil {
+			return registryV1Source{}, fmt.Errorf("directory %q in commit %s: %w", dir, commit.Hash, err)
+		}
+	}
+
+	fsys, err := treeFS(tree)
+	if err != nil {
+		return registryV1Source{}, fmt.Errorf("failed to read commit %s: %w", commit.Hash, err)
+	}
+	sourceURL, err := gitSourceURL(repo, repoPath)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+
+	return registryV1Source{
+		fsys:     fsys,
+		location: fmt.Sprintf("%s@%s", src.Repository, commit.Hash),
+		sourceFiles: func() ([]FileReport, error) {
+			// Files are identified by the git revision syntax,
+			// <repository>@<commit>:<path>, since they are not read from
+			// disk.
+			var files []FileReport
+			if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					return err
+				}
+				file, err := newFileReport(fmt.Sprintf("%s@%s:%s", src.Repository, commit.Hash, path.Join(dir, name)), bytes.NewReader(data))
+				if err != nil {
+					return err
+				}
+				files = append(files, *file)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+			return files, nil
+		},
+		revision: &SourceRevision{
+			Repository: sourceURL,
+			Revision:   src.Revision,
+			Commit:     commit.Hash.String(),
+		},
+		imageAnnotations: map[string]string{
+			ocispec.AnnotationRevision: commit.Hash.String(),
+			ocispec.AnnotationSource:   sourceURL,
+		},
+	}, nil
+}
+
+func openGitRepository(repository string, workingDir string) (string, *git.Repository, error) {
+	repoPath, err := gitRepositoryPath(repository, workingDir)
+	if err != nil {
+		return "", nil, err
+	}
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open git repository %q: %w", repoPath, err)
+	}
+	return repoPath, repo, nil
+}
+
+// openConfinedGitRepository opens the repository at the local path
+// repository within root. Its git directory must be a directory within
+// root, and the repository is read through a filesystem bound to it, so
+// that neither symlinks nor alternate object directories can escape it.
+func openConfinedGitRepository(root *os.Root, repository string, workingDir string) (string, *git.Repository, error) {
+	if repository == "" {
+		return "", nil, fmt.Errorf("source.git.repository is required")
+	}
+	if strings.Contains(repository, "://") {
+		return "", nil, fmt.Errorf("source.git.repository URLs are not supported in specs confined to the spec file's directory")
+	}
+	name, err := confinedPath(root, "source.git.repository", repository)
+	if err != nil {
+		return "", nil, err
+	}
+	repoPath := filepath.Join(workingDir, repository)
+
+	// Like git, open the git directory of a working tree, or else the
+	// repository as a bare repository.
+	gitDir := filepath.Join(root.Name(), filepath.FromSlash(name))
+	if info, err := root.Lstat(path.Join(name, git.GitDirName)); err == nil && info.IsDir() {
+		gitDir = filepath.Join(gitDir, git.GitDirName)
+	}
+	storage := filesystem.NewStorage(osfs.New(gitDir, osfs.WithBoundOS()), cache.NewObjectLRUDefault())
+	repo, err := git.Open(storage, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open git repository %q: %w", repoPath, err)
+	}
+	return repoPath, repo, nil
+}
+
+// gitRepositoryPath returns the path of the repository, which is a path
+// relative to workingDir or a file:// URL.
+func gitRepositoryPath(repository string, workingDir string) (string, error) {
+	if repository == "" {
+		return "", fmt.Errorf("source.git.repository is required")
+	}
+	if !strings.HasPrefix(repository, fileURLPrefix) {
+		if strings.Contains(repository, "://") {
+			return "", fmt.Errorf("unsupported git repository URL %q: only local repositories and file:// URLs are supported", repository)
+		}
+		return filepath.Join(workingDir, repository), nil
+	}
+	u, err := url.Parse(repository)
+	if err != nil {
+		return "", err
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("unsupported git repository URL %q: file:// URLs must not have a host", repository)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// gitSourceURL returns the URL the repository at repoPath was cloned from,
+// i.e. the URL of its origin remote, or else a file:// URL of repoPath. The
+// user information of the origin URL, which may hold credentials, is removed,
+// because the URL is recorded in image annotations and build reports.
+func gitSourceURL(repo *git.Repository, repoPath string) (string, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err == nil && len(remote.Config().URLs) > 0 {
+		rawURL := remote.Config().URLs[0]
+		// scp-like URLs such as git@github.com:org/repo.git do not parse as
+		// URLs, and their user is not a credential.
+		if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" && u.User != nil {
+			u.User = nil
+			return u.String(), nil
+		}
+		return rawURL, nil
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String(), nil
+}
+
+// treeFS returns an in-memory filesystem containing the files of tree.
+func treeFS(tree *object.Tree) (fs.FS, error) {
+	fsys := fstest.MapFS{}
+	if err := tree.Files().ForEach(func(f *object.File) error {
+		var mode fs.FileMode
+		switch f.Mode {
+		case filemode.Regular, filemode.Deprecated:
+			mode = 0o644
+		case filemode.Executable:
+			mode = 0o755
+		default:
+			return fmt.Errorf("unsupported file mode %s for %q", f.Mode, f.Name)
+		}
+		r, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		fsys[f.Name] = &fstest.MapFile{Data: data, Mode: mode}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return fsys, nil
+}
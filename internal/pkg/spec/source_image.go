@@ -0,0 +1,117 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+	"github.com/operator-framework/kpm/internal/pkg/util/registryclient"
+)
+
+const (
+	ociLayoutPrefix  = "oci-layout:"
+	ociArchivePrefix = "oci-archive:"
+)
+
+// loadImageSource reads the bundle image ref, which is an image registry
+// reference, an oci-layout: directory, or an oci-archive: file. Registry
+// references are normalized like container runtimes normalize them, e.g.
+// nginx refers to docker.io/library/nginx:latest. Relative layout and archive
+// paths are relative to workingDir.
+func loadImageSource(ctx context.Context, ref string, workingDir string, plainHTTP bool) (registryV1Source, error) {
+	var (
+		fetcher     content.Fetcher
+		desc        ocispec.Descriptor
+		sourceFiles func() ([]FileReport, error)
+	)
+	switch {
+	case strings.HasPrefix(ref, ociLayoutPrefix), strings.HasPrefix(ref, ociArchivePrefix):
+		path, manifestRef := splitLayoutReference(strings.TrimPrefix(strings.TrimPrefix(ref, ociLayoutPrefix), ociArchivePrefix), workingDir)
+
+		var (
+			store ocilayout.ReadOnlyTarget
+			err   error
+		)
+		if strings.HasPrefix(ref, ociLayoutPrefix) {
+			if store, err = oci.NewFromFS(ctx, os.DirFS(path)); err != nil {
+				return registryV1Source{}, fmt.Errorf("failed to open OCI layout %q: %w", path, err)
+			}
+			sourceFiles = func() ([]FileReport, error) {
+				return directoryFiles(path)
+			}
+		} else {
+			if store, err = ocilayout.OpenArchive(ctx, path); err != nil {
+				return registryV1Source{}, err
+			}
+			sourceFiles = func() ([]FileReport, error) {
+				file, err := NewFileReport(path)
+				if err != nil {
+					return nil, err
+				}
+				file.Path = filepath.ToSlash(file.Path)
+				return []FileReport{*file}, nil
+			}
+		}
+		if desc, err = ocilayout.ResolveManifest(ctx, store, manifestRef); err != nil {
+			return registryV1Source{}, fmt.Errorf("%s: %w", ref, err)
+		}
+		fetcher = store
+	default:
+		image, err := registryclient.ParseReference(ref)
+		if err != nil {
+			return registryV1Source{}, err
+		}
+		repo, err := registryclient.NewRepository(ref, plainHTTP)
+		if err != nil {
+			return registryV1Source{}, err
+		}
+		if desc, err = repo.Resolve(ctx, repo.Reference.ReferenceOrDefault()); err != nil {
+			return registryV1Source{}, fmt.Errorf("failed to resolve %q: %w", ref, err)
+		}
+		// The image is identified by its manifest digest rather than by
+		// files on disk.
+		image.Reference = desc.Digest.String()
+		sourceFiles = func() ([]FileReport, error) {
+			return []FileReport{{Path: image.String(), Size: desc.Size, Digest: desc.Digest}}, nil
+		}
+		fetcher = repo
+	}
+
+	manifest, err := ocilayout.FetchManifest(ctx, fetcher, desc)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	fsys, err := ocilayout.LayersFS(ctx, fetcher, manifest)
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	return registryV1Source{fsys: fsys, sourceFiles: sourceFiles}, nil
+}
+
+// splitLayoutReference splits the path of an OCI layout or archive, relative
+// to workingDir, from the tag (path:tag) or digest (path@digest) that follows
+// it, if any. Since tags in OCI layouts may be full image references that
+// contain colons themselves (e.g. path:example:1.2.3), the path ends at the
+// first colon that follows an existing path.
+func splitLayoutReference(s string, workingDir string) (string, string) {
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		return filepath.Join(workingDir, s[:i]), s[i+1:]
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != ':' {
+			continue
+		}
+		path := filepath.Join(workingDir, s[:i])
+		if _, err := os.Stat(path); err == nil {
+			return path, s[i+1:]
+		}
+	}
+	return filepath.Join(workingDir, s), ""
+}
@@ -0,0 +1,128 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/oci"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+	"github.com/operator-framework/kpm/internal/pkg/util/ocilayout"
+)
+
+// writeTestBundle writes a minimal registry+v1 bundle directory to dir.
+func writeTestBundle(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"manifests/csv.yaml": `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+`,
+		"metadata/annotations.yaml": `
+annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: example
+`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+}
+
+func Test_loadImageSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, filepath.Join(dir, "bundle"))
+	b, err := registryv1.NewBundleFSLoader(os.DirFS(filepath.Join(dir, "bundle"))).Load()
+	require.NoError(t, err)
+
+	layout, err := oci.NewWithContext(t.Context(), filepath.Join(dir, "layout"))
+	require.NoError(t, err)
+	desc, err := b.MarshalOCI(t.Context(), layout)
+	require.NoError(t, err)
+	require.NoError(t, ocilayout.WriteArchive(t.Context(), filepath.Join(dir, "bundle.kpm"), func(store *oci.Store) error {
+		_, err := b.MarshalOCI(t.Context(), store)
+		return err
+	}))
+
+	tests := []struct {
+		name          string
+		reference     string
+		expectedFiles int
+		expectedErr   string
+	}{
+		{
+			name:          "archive",
+			reference:     "oci-archive:bundle.kpm",
+			expectedFiles: 1,
+		},
+		{
+			name:          "layout with full reference tag",
+			reference:     "oci-layout:layout:example:1.2.3",
+			expectedFiles: 5,
+		},
+		{
+			name:          "layout with digest",
+			reference:     "oci-layout:layout@" + desc.Digest.String(),
+			expectedFiles: 5,
+		},
+		{
+			name:        "unknown tag",
+			reference:   "oci-layout:layout:example:4.5.6",
+			expectedErr: `failed to resolve reference "example:4.5.6"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{Source: specsv1.RegistryV1Source{
+				SourceType: specsv1.RegistryV1SourceTypeImage,
+				Image:      &specsv1.RegistryV1ImageSource{Reference: tt.reference},
+			}}, dir, LoadOptions{})
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "example.v1.2.3", s.ID())
+
+			files, err := s.(SourceSpec).SourceFiles()
+			require.NoError(t, err)
+			require.Len(t, files, tt.expectedFiles)
+		})
+	}
+}
+
+func Test_splitLayoutReference(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "layout"), 0o755))
+
+	tests := []struct {
+		name         string
+		s            string
+		expectedPath string
+		expectedRef  string
+	}{
+		{name: "path only", s: "layout", expectedPath: "layout"},
+		{name: "tag", s: "layout:v1", expectedPath: "layout", expectedRef: "v1"},
+		{name: "full reference tag", s: "layout:quay.io/example/bundle:v1", expectedPath: "layout", expectedRef: "quay.io/example/bundle:v1"},
+		{name: "digest", s: "layout@sha256:abc", expectedPath: "layout", expectedRef: "sha256:abc"},
+		{name: "missing path", s: "missing:v1", expectedPath: "missing:v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ref := splitLayoutReference(tt.s, dir)
+			require.Equal(t, filepath.Join(dir, tt.expectedPath), path)
+			require.Equal(t, tt.expectedRef, ref)
+		})
+	}
+}
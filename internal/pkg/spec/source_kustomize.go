@@ -0,0 +1,117 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing/fstest"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+	registryv1 "github.com/operator-framework/kpm/internal/pkg/bundle/registry/v1"
+)
+
+// loadKustomizeSource renders the kustomization in src.Directory, relative
+// to workingDir, into the manifests of a bundle whose metadata is generated
+// from src.
+func loadKustomizeSource(src specsv1.RegistryV1KustomizeSource, workingDir string) (registryV1Source, error) {
+	if src.Directory == "" {
+		return registryV1Source{}, fmt.Errorf("source.kustomize.directory is required")
+	}
+	dir := filepath.Join(workingDir, src.Directory)
+
+	fSys := &recordingFS{FileSystem: filesys.MakeFsOnDisk(), files: sets.New[string]()}
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, dir)
+	if err != nil {
+		return registryV1Source{}, fmt.Errorf("failed to render kustomization %q: %w", dir, err)
+	}
+
+	fsys := fstest.MapFS{}
+	for _, res := range resMap.Resources() {
+		name := "manifests/" + manifestFileName(res)
+		if _, ok := fsys[name]; ok {
+			return registryV1Source{}, fmt.Errorf("kustomization %q renders several objects named %s", dir, res.CurId())
+		}
+		data, err := res.AsYAML()
+		if err != nil {
+			return registryV1Source{}, err
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	annotations, err := yaml.Marshal(registryv1.NewAnnotations(src.Package, src.Channels, src.DefaultChannel))
+	if err != nil {
+		return registryV1Source{}, err
+	}
+	fsys["metadata/annotations.yaml"] = &fstest.MapFile{Data: annotations}
+
+	return registryV1Source{fsys: fsys, location: fmt.Sprintf("kustomization %q", dir), sourceFiles: fSys.fileReports}, nil
+}
+
+// manifestFileName returns the canonical bundle manifest file name of res,
+// <name>_<group>_<version>_<kind>.yaml, where the group is omitted for the
+// core group.
+func manifestFileName(res *resource.Resource) string {
+	gvk := res.GetGvk()
+	parts := []string{res.GetName()}
+	if gvk.Group != "" {
+		parts = append(parts, gvk.Group)
+	}
+	parts = append(parts, gvk.Version, strings.ToLower(gvk.Kind))
+	return strings.Join(parts, "_") + ".yaml"
+}
+
+// recordingFS is a kustomize filesystem that records the files read from
+// it, which are the source files of a kustomization.
+type recordingFS struct {
+	filesys.FileSystem
+
+	mu    sync.Mutex
+	files sets.Set[string]
+}
+
+func (f *recordingFS) ReadFile(name string) ([]byte, error) {
+	data, err := f.FileSystem.ReadFile(name)
+	if err == nil {
+		f.mu.Lock()
+		f.files.Insert(name)
+		f.mu.Unlock()
+	}
+	return data, err
+}
+
+// fileReports describes the files read from f, with paths relative to the
+// current directory where possible.
+func (f *recordingFS) fileReports() ([]FileReport, error) {
+	f.mu.Lock()
+	names := sets.List(f.files)
+	f.mu.Unlock()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]FileReport, 0, len(names))
+	for _, name := range names {
+		file, err := NewFileReport(name)
+		if err != nil {
+			return nil, err
+		}
+		if rel, err := filepath.Rel(cwd, name); err == nil && filepath.IsLocal(rel) {
+			file.Path = rel
+		}
+		file.Path = filepath.ToSlash(file.Path)
+		files = append(files, *file)
+	}
+	slices.SortFunc(files, func(a, b FileReport) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
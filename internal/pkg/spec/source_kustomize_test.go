@@ -0,0 +1,131 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	specsv1 "github.com/operator-framework/kpm/internal/api/specs/v1"
+)
+
+func Test_loadKustomizeSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config/manifests/kustomization.yaml": `
+resources:
+  - csv.yaml
+  - ../rbac
+commonAnnotations:
+  example.com/kustomized: "true"
+`,
+		"config/manifests/csv.yaml": `
+apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: example.v1.2.3
+spec:
+  version: "1.2.3"
+`,
+		"config/rbac/kustomization.yaml": `
+resources:
+  - role.yaml
+  - service.yaml
+`,
+		"config/rbac/role.yaml": `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metrics-reader
+rules: []
+`,
+		"config/rbac/service.yaml": `
+apiVersion: v1
+kind: Service
+metadata:
+  name: metrics
+spec:
+  ports:
+    - port: 8443
+`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+	t.Chdir(dir)
+
+	t.Run("renders manifests", func(t *testing.T) {
+		s, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{Source: specsv1.RegistryV1Source{
+			SourceType: specsv1.RegistryV1SourceTypeKustomize,
+			Kustomize: &specsv1.RegistryV1KustomizeSource{
+				Directory:      "config/manifests",
+				Package:        "example",
+				Channels:       []string{"stable", "fast"},
+				DefaultChannel: "stable",
+			},
+		}}, dir, LoadOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "example.v1.2.3", s.ID())
+
+		summary := s.(*registryV1Spec).Summary()
+		require.Equal(t, []string{"stable", "fast"}, summary.Channels)
+		require.Equal(t, "stable", summary.DefaultChannel)
+
+		var names []string
+		for f := range s.(*registryV1Spec).Manifests().All() {
+			names = append(names, f.Name())
+			require.Equal(t, "true", f.Value().GetAnnotations()["example.com/kustomized"])
+		}
+		require.ElementsMatch(t, []string{
+			"example.v1.2.3_operators.coreos.com_v1alpha1_clusterserviceversion.yaml",
+			"metrics-reader_rbac.authorization.k8s.io_v1_clusterrole.yaml",
+			"metrics_v1_service.yaml",
+		}, names)
+
+		sourceFiles, err := s.(SourceSpec).SourceFiles()
+		require.NoError(t, err)
+		var paths []string
+		for _, f := range sourceFiles {
+			paths = append(paths, f.Path)
+		}
+		require.Equal(t, []string{
+			"config/manifests/csv.yaml",
+			"config/manifests/kustomization.yaml",
+			"config/rbac/kustomization.yaml",
+			"config/rbac/role.yaml",
+			"config/rbac/service.yaml",
+		}, paths)
+	})
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{Source: specsv1.RegistryV1Source{
+			SourceType: specsv1.RegistryV1SourceTypeKustomize,
+			Kustomize:  &specsv1.RegistryV1KustomizeSource{Directory: "config/manifests"},
+		}}, dir, LoadOptions{})
+		require.ErrorContains(t, err, `invalid value for annotation key "operators.operatorframework.io.bundle.package.v1"`)
+	})
+	t.Run("metadata from overrides", func(t *testing.T) {
+		s, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{
+			Source: specsv1.RegistryV1Source{
+				SourceType: specsv1.RegistryV1SourceTypeKustomize,
+				Kustomize:  &specsv1.RegistryV1KustomizeSource{Directory: "config/manifests"},
+			},
+			Annotations: &specsv1.RegistryV1AnnotationsOverride{Values: map[string]string{
+				"operators.operatorframework.io.bundle.package.v1":  "example",
+				"operators.operatorframework.io.bundle.channels.v1": "stable",
+			}},
+		}, dir, LoadOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "example.v1.2.3", s.ID())
+		require.Equal(t, []string{"stable"}, s.(*registryV1Spec).Summary().Channels)
+	})
+	t.Run("missing kustomization", func(t *testing.T) {
+		_, err := loadRegistryV1(t.Context(), specsv1.RegistryV1{Source: specsv1.RegistryV1Source{
+			SourceType: specsv1.RegistryV1SourceTypeKustomize,
+			Kustomize:  &specsv1.RegistryV1KustomizeSource{Directory: "config", Package: "example"},
+		}}, dir, LoadOptions{})
+		require.ErrorContains(t, err, "failed to render kustomization")
+	})
+}
@@ -11,3 +11,15 @@ type Spec interface {
 	ID() string
 	MarshalOCI(context.Context, oras.Target) (ocispec.Descriptor, error)
 }
+
+// WarningsSpec is a spec that produced warnings while it was loaded, which
+// are included in its build report.
+type WarningsSpec interface {
+	Warnings() []string
+}
+
+// SourceRevisionSpec is a spec built from a commit of a version control
+// repository, which is recorded in its build report.
+type SourceRevisionSpec interface {
+	SourceRevision() *SourceRevision
+}
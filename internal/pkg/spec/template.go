@@ -0,0 +1,113 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// nondeterministicFuncs are the sprig functions whose results depend on the
+// current time, randomness, or the environment. Spec files that use them
+// would not build reproducibly, so they are rejected unless explicitly
+// allowed.
+var nondeterministicFuncs = []string{
+	"now", "ago",
+	"randAlpha", "randAlphaNum", "randAscii", "randNumeric", "randBytes", "randInt", "shuffle",
+	"uuidv4",
+	"bcrypt", "htpasswd", "encryptAES",
+	"genPrivateKey", "genCA", "genCAWithKey", "genSelfSignedCert", "genSelfSignedCertWithKey", "genSignedCert", "genSignedCertWithKey",
+	"env", "expandenv",
+	"getHostByName",
+}
+
+// TemplateOptions configure the rendering of spec file templates.
+type TemplateOptions struct {
+	// Values are available to templates as .Values.
+	Values map[string]any
+	// AllowNondeterministic enables template functions whose results depend
+	// on the current time, randomness, or the environment.
+	AllowNondeterministic bool
+}
+
+// RenderTemplate renders data as a Go template with sprig functions. Missing
+// values are an error, so that typos in value names do not silently render
+// as empty strings.
+func RenderTemplate(name string, data []byte, opts TemplateOptions) ([]byte, error) {
+	funcs := sprig.TxtFuncMap()
+	if !opts.AllowNondeterministic {
+		for _, fn := range nondeterministicFuncs {
+			funcs[fn] = nondeterministicFunc(fn)
+		}
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	values := opts.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{"Values": values}); err != nil {
+		return nil, fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func nondeterministicFunc(name string) func(...any) (any, error) {
+	return func(...any) (any, error) {
+		return nil, fmt.Errorf("function %q is nondeterministic and is disabled to keep builds reproducible", name)
+	}
+}
+
+// MergeValues merges src into dst. Nested maps are merged recursively, and
+// all other values in src replace those in dst.
+func MergeValues(dst, src map[string]any) {
+	for k, srcV := range src {
+		srcMap, srcIsMap := srcV.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			MergeValues(dstMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			dstMap = map[string]any{}
+			MergeValues(dstMap, srcMap)
+			dst[k] = dstMap
+			continue
+		}
+		dst[k] = srcV
+	}
+}
+
+// SetValue parses an assignment of the form "a.b.c=value" and sets the string
+// value at the dotted key path in values, creating intermediate maps as
+// needed.
+func SetValue(values map[string]any, assignment string) error {
+	key, value, ok := strings.Cut(assignment, "=")
+	if !ok {
+		return fmt.Errorf("invalid value %q: expected key=value", assignment)
+	}
+	path := strings.Split(key, ".")
+	for _, segment := range path {
+		if segment == "" {
+			return fmt.Errorf("invalid value %q: empty key segment", assignment)
+		}
+	}
+
+	m := values
+	for _, segment := range path[:len(path)-1] {
+		next, ok := m[segment].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[segment] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+	return nil
+}
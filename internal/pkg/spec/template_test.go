@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RenderTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		opts      TemplateOptions
+		expected  string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:      "no template actions",
+			template:  "path: ./bundle\n",
+			expected:  "path: ./bundle\n",
+			assertErr: require.NoError,
+		},
+		{
+			name:      "values and sprig functions",
+			template:  `path: ./{{ .Values.version | trimPrefix "v" }}`,
+			opts:      TemplateOptions{Values: map[string]any{"version": "v1.2.3"}},
+			expected:  "path: ./1.2.3",
+			assertErr: require.NoError,
+		},
+		{
+			name:     "missing value",
+			template: `path: ./{{ .Values.version }}`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, `map has no entry for key "version"`)
+			},
+		},
+		{
+			name:     "nondeterministic function",
+			template: `id: {{ uuidv4 }}`,
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, `function "uuidv4" is nondeterministic`)
+			},
+		},
+		{
+			name:      "nondeterministic function allowed",
+			template:  `{{ if now }}ok{{ end }}`,
+			opts:      TemplateOptions{AllowNondeterministic: true},
+			expected:  "ok",
+			assertErr: require.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := RenderTemplate("test", []byte(tt.template), tt.opts)
+			tt.assertErr(t, err)
+			if err == nil {
+				require.Equal(t, tt.expected, string(actual))
+			}
+		})
+	}
+}
+
+func Test_Values(t *testing.T) {
+	values := map[string]any{}
+	MergeValues(values, map[string]any{"image": map[string]any{"name": "operator", "tag": "v1"}, "replicas": 1})
+	MergeValues(values, map[string]any{"image": map[string]any{"tag": "v2"}})
+	require.NoError(t, SetValue(values, "image.registry=quay.io"))
+	require.NoError(t, SetValue(values, "channel.default=stable=fast"))
+	require.Equal(t, map[string]any{
+		"image":    map[string]any{"name": "operator", "tag": "v2", "registry": "quay.io"},
+		"replicas": 1,
+		"channel":  map[string]any{"default": "stable=fast"},
+	}, values)
+
+	require.ErrorContains(t, SetValue(values, "novalue"), "expected key=value")
+	require.ErrorContains(t, SetValue(values, "a..b=c"), "empty key segment")
+}
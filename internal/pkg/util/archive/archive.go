@@ -0,0 +1,152 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"testing/fstest"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// maxContentSize is the maximum total size of the files in an archive, which
+// are decompressed into memory, so that a small compressed archive cannot
+// exhaust memory.
+var maxContentSize int64 = 1 << 30
+
+// FS returns an in-memory filesystem containing the contents of the tar,
+// gzip-compressed tar, or zip archive data. The format is detected from the
+// data rather than from a file name, so that archives downloaded from URLs
+// without a telling extension work too. The total size of the files must not
+// exceed 1 GiB.
+func FS(data []byte) (fs.FS, error) {
+	switch {
+	case bytes.HasPrefix(data, zipMagic):
+		return zipFS(data)
+	case bytes.HasPrefix(data, gzipMagic):
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return tarFS(zr)
+	default:
+		return tarFS(bytes.NewReader(data))
+	}
+}
+
+func tarFS(r io.Reader) (fs.FS, error) {
+	fsys := fstest.MapFS{}
+	b := budget{remaining: maxContentSize}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fsys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch header.Typeflag {
+		case tar.TypeXGlobalHeader:
+			// e.g. the commit ID written by git archive.
+			continue
+		case tar.TypeDir, tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("unsupported entry type %q for %q", string(header.Typeflag), header.Name)
+		}
+
+		name, err := cleanName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "." {
+			continue
+		}
+		if header.Typeflag == tar.TypeDir {
+			fsys[name] = &fstest.MapFile{Mode: fs.ModeDir | fs.FileMode(header.Mode).Perm()}
+			continue
+		}
+		data, err := b.readAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		fsys[name] = &fstest.MapFile{Data: data, Mode: fs.FileMode(header.Mode).Perm()}
+	}
+}
+
+func zipFS(data []byte) (fs.FS, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	fsys := fstest.MapFS{}
+	b := budget{remaining: maxContentSize}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			return nil, fmt.Errorf("unsupported entry type %q for %q", mode.Type(), f.Name)
+		}
+		name, err := cleanName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "." {
+			continue
+		}
+		if mode.IsDir() {
+			fsys[name] = &fstest.MapFile{Mode: fs.ModeDir | mode.Perm()}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := b.readAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		fsys[name] = &fstest.MapFile{Data: data, Mode: mode.Perm()}
+	}
+	return fsys, nil
+}
+
+// budget limits the total size of the files read from an archive.
+type budget struct {
+	remaining int64
+}
+
+// readAll reads the contents of a file from r, failing if they exceed the
+// remaining budget.
+func (b *budget) readAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, b.remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > b.remaining {
+		return nil, fmt.Errorf("archive contents are larger than %d bytes", maxContentSize)
+	}
+	b.remaining -= int64(len(data))
+	return data, nil
+}
+
+// cleanName returns the fs.FS path of the archive entry name, rejecting names
+// that escape the archive root.
+func cleanName(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "/"))
+	if !fs.ValidPath(cleaned) {
+		return "", fmt.Errorf("invalid path %q in archive", name)
+	}
+	return cleaned, nil
+}
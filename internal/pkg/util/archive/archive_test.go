@@ -0,0 +1,67 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FS_MaxContentSize(t *testing.T) {
+	defer func(size int64) { maxContentSize = size }(maxContentSize)
+
+	files := map[string][]byte{
+		"a.yaml": bytes.Repeat([]byte("a"), 1<<10),
+		"b.yaml": bytes.Repeat([]byte("b"), 1<<20),
+	}
+	var tarData bytes.Buffer
+	tw := tar.NewWriter(&tarData)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	var tgzData bytes.Buffer
+	gw := gzip.NewWriter(&tgzData)
+	_, err := gw.Write(tarData.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	for name, data := range map[string][]byte{
+		"tar":    tarData.Bytes(),
+		"tar.gz": tgzData.Bytes(),
+		"zip":    zipData.Bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			maxContentSize = 1<<20 + 1<<10
+			fsys, err := FS(data)
+			require.NoError(t, err)
+			actual, err := fs.ReadFile(fsys, "b.yaml")
+			require.NoError(t, err)
+			require.Equal(t, files["b.yaml"], actual)
+
+			// The compressed archives are much smaller than their contents,
+			// which must still be rejected.
+			maxContentSize = 1<<20 + 1<<10 - 1
+			_, err = FS(data)
+			require.ErrorContains(t, err, "archive contents are larger than 1049599 bytes")
+		})
+	}
+	require.Less(t, tgzData.Len(), 1<<14)
+}
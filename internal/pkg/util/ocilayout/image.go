@@ -0,0 +1,156 @@
+package ocilayout
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"testing/fstest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	mediaTypeDockerManifest  = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// FetchManifest fetches and decodes the image manifest described by desc.
+func FetchManifest(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, mediaTypeDockerManifest:
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q", desc.MediaType)
+	}
+	var manifest ocispec.Manifest
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", desc.Digest, err)
+	}
+	return &manifest, nil
+}
+
+// FetchConfig fetches and decodes the image config of manifest.
+func FetchConfig(ctx context.Context, fetcher content.Fetcher, manifest *ocispec.Manifest) (*ocispec.Image, error) {
+	var cfg ocispec.Image
+	if err := fetchJSON(ctx, fetcher, manifest.Config, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to fetch config %s: %w", manifest.Config.Digest, err)
+	}
+	return &cfg, nil
+}
+
+func fetchJSON(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, v any) error {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// LayersFS returns an in-memory filesystem containing the contents of the
+// layers of manifest, applied in order.
+func LayersFS(ctx context.Context, fetcher content.Fetcher, manifest *ocispec.Manifest) (fs.FS, error) {
+	fsys := fstest.MapFS{}
+	for _, layer := range manifest.Layers {
+		if err := applyLayer(ctx, fetcher, layer, fsys); err != nil {
+			return nil, fmt.Errorf("failed to apply layer %s: %w", layer.Digest, err)
+		}
+	}
+	return fsys, nil
+}
+
+// IsTarLayer reports whether desc describes a tar layer, optionally gzipped,
+// that OpenLayer can open.
+func IsTarLayer(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageLayerGzip, mediaTypeDockerLayerGzip, ocispec.MediaTypeImageLayer:
+		return true
+	default:
+		return false
+	}
+}
+
+// OpenLayer fetches the layer described by desc and returns a reader for its
+// uncompressed tar stream.
+func OpenLayer(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (io.Reader, error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, err
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageLayerGzip, mediaTypeDockerLayerGzip:
+		return gzip.NewReader(bytes.NewReader(data))
+	case ocispec.MediaTypeImageLayer:
+		return bytes.NewReader(data), nil
+	default:
+		return nil, fmt.Errorf("unsupported layer media type %q", desc.MediaType)
+	}
+}
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+func applyLayer(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, fsys fstest.MapFS) error {
+	r, err := OpenLayer(ctx, fetcher, desc)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if name == "." {
+			continue
+		}
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("invalid path %q in layer", header.Name)
+		}
+
+		dir, base := path.Split(name)
+		if base == whiteoutOpaque {
+			removeTree(fsys, path.Clean(dir), false)
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			removeTree(fsys, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true)
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			fsys[name] = &fstest.MapFile{Mode: fs.ModeDir | fs.FileMode(header.Mode).Perm()}
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			fsys[name] = &fstest.MapFile{Data: data, Mode: fs.FileMode(header.Mode).Perm()}
+		default:
+			return fmt.Errorf("unsupported entry type %q for %q", string(header.Typeflag), header.Name)
+		}
+	}
+}
+
+func removeTree(fsys fstest.MapFS, root string, includeRoot bool) {
+	for name := range fsys {
+		if root == "." || (includeRoot && name == root) || strings.HasPrefix(name, root+"/") {
+			delete(fsys, name)
+		}
+	}
+}
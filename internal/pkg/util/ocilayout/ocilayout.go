@@ -0,0 +1,200 @@
+package ocilayout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/renameio/v2"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/tar"
+)
+
+// ReadOnlyTarget is a read-only OCI target whose tags can be listed, such as
+// an OCI layout opened from a .kpm file.
+type ReadOnlyTarget interface {
+	oras.ReadOnlyGraphTarget
+	registry.TagLister
+}
+
+// OpenArchive opens the tarred OCI image layout at path (e.g. a .kpm file)
+// as a read-only target.
+func OpenArchive(ctx context.Context, path string) (*oci.ReadOnlyStore, error) {
+	store, err := oci.NewFromTar(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OCI archive %q: %w", path, err)
+	}
+	return store, nil
+}
+
+// WriteArchive populates a new OCI image layout using fn and then atomically
+// writes that layout as a tar archive to path.
+func WriteArchive(ctx context.Context, path string, fn func(*oci.Store) error) error {
+	layout, err := NewTempLayout(ctx)
+	if err != nil {
+		return err
+	}
+	defer layout.Close()
+
+	if err := fn(layout.Store); err != nil {
+		return err
+	}
+	return layout.WriteArchive(path)
+}
+
+// UpdateArchive opens the tarred OCI image layout at path as a writable
+// layout, updates it using fn, and then atomically replaces the archive at
+// path with the updated layout.
+func UpdateArchive(ctx context.Context, path string, fn func(*oci.Store) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "kpm-oci-layout-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := tar.Extract(f, dir); err != nil {
+		return fmt.Errorf("failed to extract OCI archive %q: %w", path, err)
+	}
+	store, err := oci.NewWithContext(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to open OCI archive %q: %w", path, err)
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return writeDirectoryArchive(path, dir)
+}
+
+// TempLayout is an OCI image layout in a temporary directory. It is useful
+// when the archive path depends on the layout's contents.
+type TempLayout struct {
+	*oci.Store
+	dir string
+}
+
+func NewTempLayout(ctx context.Context) (*TempLayout, error) {
+	dir, err := os.MkdirTemp("", "kpm-oci-layout-*")
+	if err != nil {
+		return nil, err
+	}
+	store, err := oci.NewWithContext(ctx, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &TempLayout{Store: store, dir: dir}, nil
+}
+
+// WriteArchive atomically writes the layout as a tar archive to path.
+func (l *TempLayout) WriteArchive(path string) error {
+	return writeDirectoryArchive(path, l.dir)
+}
+
+// Close removes the layout's temporary directory.
+func (l *TempLayout) Close() error {
+	return os.RemoveAll(l.dir)
+}
+
+func writeDirectoryArchive(path string, dir string) error {
+	pf, err := renameio.NewPendingFile(path)
+	if err != nil {
+		return err
+	}
+	defer pf.Cleanup()
+
+	if err := tar.Directory(pf, os.DirFS(dir)); err != nil {
+		return err
+	}
+	return pf.CloseAtomicallyReplace()
+}
+
+// Tags returns all tags in the target, in ascending order.
+func Tags(ctx context.Context, target registry.TagLister) ([]string, error) {
+	var tags []string
+	if err := target.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ResolveManifest resolves ref in target. If ref is empty, the target must
+// contain tags that all refer to the same manifest, and that manifest is
+// returned.
+func ResolveManifest(ctx context.Context, target ReadOnlyTarget, ref string) (ocispec.Descriptor, error) {
+	if ref != "" {
+		desc, err := target.Resolve(ctx, ref)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to resolve reference %q: %w", ref, err)
+		}
+		return desc, nil
+	}
+
+	tags, err := Tags(ctx, target)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return ocispec.Descriptor{}, errors.New("no tagged manifests found")
+	}
+
+	var (
+		resolved ocispec.Descriptor
+		digests  []string
+	)
+	for _, tag := range tags {
+		desc, err := target.Resolve(ctx, tag)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to resolve tag %q: %w", tag, err)
+		}
+		if !slices.Contains(digests, desc.Digest.String()) {
+			digests = append(digests, desc.Digest.String())
+		}
+		resolved = desc
+	}
+	if len(digests) > 1 {
+		return ocispec.Descriptor{}, fmt.Errorf("found multiple tagged manifests %v: a reference is required", digests)
+	}
+	return resolved, nil
+}
+
+// ManifestTags returns the tags in target that refer to the manifest
+// described by desc, in ascending order. For tags that are full references
+// (e.g. "example:1.2.3"), only the tag portion is returned.
+func ManifestTags(ctx context.Context, target ReadOnlyTarget, desc ocispec.Descriptor) ([]string, error) {
+	refs, err := Tags(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	var tags []string
+	for _, ref := range refs {
+		refDesc, err := target.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag %q: %w", ref, err)
+		}
+		if refDesc.Digest != desc.Digest {
+			continue
+		}
+		tag := ref[strings.LastIndex(ref, ":")+1:]
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
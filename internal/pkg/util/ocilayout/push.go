@@ -0,0 +1,25 @@
+package ocilayout
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// Push copies the manifest described by desc, along with its config, layers
+// and referrers (e.g. signatures), from src to dst and applies each of tags
+// to it.
+func Push(ctx context.Context, src content.ReadOnlyGraphStorage, desc ocispec.Descriptor, dst oras.Target, tags []string) error {
+	if err := oras.ExtendedCopyGraph(ctx, src, dst, desc, oras.DefaultExtendedCopyGraphOptions); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", desc.Digest, err)
+	}
+	for _, tag := range tags {
+		if err := dst.Tag(ctx, desc, tag); err != nil {
+			return fmt.Errorf("failed to tag %s as %q: %w", desc.Digest, tag, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package ocilayout
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+func Test_Push(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "test.kpm")
+	var manifestDesc ocispec.Descriptor
+	require.NoError(t, WriteArchive(t.Context(), archivePath, func(store *oci.Store) error {
+		var err error
+		manifestDesc, err = pushTestManifest(t.Context(), store, []byte("layer"), "example:1.2.3")
+		return err
+	}))
+
+	src, err := OpenArchive(t.Context(), archivePath)
+	require.NoError(t, err)
+
+	desc, err := ResolveManifest(t.Context(), src, "")
+	require.NoError(t, err)
+	require.Equal(t, manifestDesc.Digest, desc.Digest)
+
+	// An in-memory store stands in for a remote registry repository.
+	dst := memory.New()
+	require.NoError(t, Push(t.Context(), src, desc, dst, []string{"1.2.3", "latest"}))
+
+	for _, ref := range []string{"1.2.3", "latest"} {
+		actual, err := dst.Resolve(t.Context(), ref)
+		require.NoError(t, err)
+		require.Equal(t, manifestDesc.Digest, actual.Digest)
+	}
+	successors, err := content.Successors(t.Context(), dst, desc)
+	require.NoError(t, err)
+	require.Len(t, successors, 2)
+	for _, blob := range successors {
+		exists, err := dst.Exists(t.Context(), blob)
+		require.NoError(t, err)
+		require.True(t, exists, "blob %s not pushed", blob.Digest)
+	}
+}
+
+func Test_ResolveManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      map[string]string
+		ref       string
+		expected  string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:      "single tag",
+			tags:      map[string]string{"example:1.2.3": "a"},
+			expected:  "a",
+			assertErr: require.NoError,
+		},
+		{
+			name:      "multiple tags, same manifest",
+			tags:      map[string]string{"example:1.2.3": "a", "example:latest": "a"},
+			expected:  "a",
+			assertErr: require.NoError,
+		},
+		{
+			name: "multiple tags, different manifests",
+			tags: map[string]string{"example:1.2.3": "a", "example:1.2.4": "b"},
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "found multiple tagged manifests")
+			},
+		},
+		{
+			name:      "multiple tags, different manifests, with reference",
+			tags:      map[string]string{"example:1.2.3": "a", "example:1.2.4": "b"},
+			ref:       "example:1.2.4",
+			expected:  "b",
+			assertErr: require.NoError,
+		},
+		{
+			name: "no tags",
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "no tagged manifests found")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := oci.NewWithContext(t.Context(), t.TempDir())
+			require.NoError(t, err)
+			manifests := map[string]ocispec.Descriptor{}
+			for tag, id := range tt.tags {
+				if _, ok := manifests[id]; !ok {
+					desc, err := pushTestManifest(t.Context(), store, []byte(id), "")
+					require.NoError(t, err)
+					manifests[id] = desc
+				}
+				require.NoError(t, store.Tag(t.Context(), manifests[id], tag))
+			}
+
+			actual, err := ResolveManifest(t.Context(), store, tt.ref)
+			tt.assertErr(t, err)
+			if tt.expected != "" {
+				require.Equal(t, manifests[tt.expected].Digest, actual.Digest)
+			}
+		})
+	}
+}
+
+func pushTestManifest(ctx context.Context, target oras.Target, layerData []byte, tag string) (ocispec.Descriptor, error) {
+	layer, err := oras.PushBytes(ctx, target, ocispec.MediaTypeImageLayerGzip, layerData)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, "application/vnd.example.test", oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if tag != "" {
+		if err := target.Tag(ctx, desc, tag); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return desc, nil
+}
+
+func Test_ManifestTags(t *testing.T) {
+	store, err := oci.NewWithContext(t.Context(), t.TempDir())
+	require.NoError(t, err)
+	a, err := pushTestManifest(t.Context(), store, []byte("a"), "example:1.2.3")
+	require.NoError(t, err)
+	require.NoError(t, store.Tag(t.Context(), a, "example:latest"))
+	require.NoError(t, store.Tag(t.Context(), a, "stable"))
+	_, err = pushTestManifest(t.Context(), store, []byte("b"), "example:1.2.4")
+	require.NoError(t, err)
+
+	tags, err := ManifestTags(t.Context(), store, a)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.3", "latest", "stable"}, tags)
+}
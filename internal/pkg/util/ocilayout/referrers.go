@@ -0,0 +1,57 @@
+package ocilayout
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Attach pushes data as an artifact of type artifactType that refers to
+// subject, i.e. an OCI referrer of subject. The artifact's manifest has a
+// single layer containing data with the given media type.
+func Attach(ctx context.Context, target oras.Target, subject ocispec.Descriptor, artifactType, mediaType string, data []byte, annotations map[string]string) (ocispec.Descriptor, error) {
+	layer, err := oras.PushBytes(ctx, target, mediaType, data)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push %s: %w", artifactType, err)
+	}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              []ocispec.Descriptor{layer},
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to attach %s to %s: %w", artifactType, subject.Digest, err)
+	}
+	return desc, nil
+}
+
+// Referrers returns the manifests in target that refer to subject. If
+// artifactType is not empty, only referrers of that type are returned.
+func Referrers(ctx context.Context, target content.ReadOnlyGraphStorage, subject ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, target, subject, artifactType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers of %s: %w", subject.Digest, err)
+	}
+	return referrers, nil
+}
+
+// FetchArtifact fetches the content of the single-layer artifact described
+// by desc, such as one created by Attach.
+func FetchArtifact(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (ocispec.Descriptor, []byte, error) {
+	manifest, err := FetchManifest(ctx, fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	if len(manifest.Layers) != 1 {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("artifact %s has %d layers, expected 1", desc.Digest, len(manifest.Layers))
+	}
+	data, err := content.FetchAll(ctx, fetcher, manifest.Layers[0])
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to fetch artifact %s: %w", desc.Digest, err)
+	}
+	return manifest.Layers[0], data, nil
+}
@@ -0,0 +1,107 @@
+package ocilayout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+
+	"github.com/operator-framework/kpm/internal/pkg/util/archive"
+)
+
+// VerifyArchive verifies the blobs of the tarred OCI image layout at path
+// (e.g. a .kpm file), like VerifyBlobs.
+func VerifyArchive(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fsys, err := archive.FS(data)
+	if err != nil {
+		return fmt.Errorf("failed to read OCI archive %q: %w", path, err)
+	}
+	return VerifyBlobs(ctx, fsys)
+}
+
+// VerifyBlobs checks the blobs of the OCI image layout in fsys. Every
+// descriptor in its index.json, tagged or not, and every descriptor reachable
+// from those must match the size and digest of the blob it refers to, and
+// every file under blobs/ must match the digest that its path names, so that
+// no blob in the layout goes unchecked.
+func VerifyBlobs(ctx context.Context, fsys fs.FS) error {
+	store, err := oci.NewFromFS(ctx, fsys)
+	if err != nil {
+		return err
+	}
+	indexData, err := fs.ReadFile(fsys, ocispec.ImageIndexFile)
+	if err != nil {
+		return err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", ocispec.ImageIndexFile, err)
+	}
+
+	verified := map[digest.Digest]bool{}
+	var verify func(ocispec.Descriptor) error
+	verify = func(desc ocispec.Descriptor) error {
+		if verified[desc.Digest] {
+			return nil
+		}
+		if _, err := content.FetchAll(ctx, store, desc); err != nil {
+			return fmt.Errorf("blob %s does not match its descriptor: %w", desc.Digest, err)
+		}
+		verified[desc.Digest] = true
+
+		successors, err := content.Successors(ctx, store, desc)
+		if err != nil {
+			return err
+		}
+		for _, next := range successors {
+			if err := verify(next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, desc := range index.Manifests {
+		if err := verify(desc); err != nil {
+			return err
+		}
+	}
+
+	return fs.WalkDir(fsys, ocispec.ImageBlobsDir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		dir, encoded := path.Split(name)
+		dgst := digest.NewDigestFromEncoded(digest.Algorithm(path.Base(dir)), encoded)
+		if path.Dir(path.Clean(dir)) != ocispec.ImageBlobsDir || dgst.Validate() != nil {
+			return fmt.Errorf("unexpected file %s in %s", name, ocispec.ImageBlobsDir)
+		}
+		if verified[dgst] {
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		verifier := dgst.Verifier()
+		if _, err := io.Copy(verifier, f); err != nil {
+			return err
+		}
+		if !verifier.Verified() {
+			return fmt.Errorf("blob %s does not match its digest", dgst)
+		}
+		return nil
+	})
+}
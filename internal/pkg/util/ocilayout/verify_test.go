@@ -0,0 +1,117 @@
+package ocilayout
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+func Test_VerifyBlobs(t *testing.T) {
+	type blobs struct {
+		layer, referrerLayer, untaggedLayer, unreferenced ocispec.Descriptor
+	}
+	tests := []struct {
+		name        string
+		tamper      func(blobs) ocispec.Descriptor
+		expectedErr string
+	}{
+		{
+			name: "untampered",
+		},
+		{
+			name:        "tampered layer",
+			tamper:      func(b blobs) ocispec.Descriptor { return b.layer },
+			expectedErr: "does not match its descriptor",
+		},
+		{
+			name:        "tampered referrer",
+			tamper:      func(b blobs) ocispec.Descriptor { return b.referrerLayer },
+			expectedErr: "does not match its descriptor",
+		},
+		{
+			name:        "tampered untagged manifest",
+			tamper:      func(b blobs) ocispec.Descriptor { return b.untaggedLayer },
+			expectedErr: "does not match its descriptor",
+		},
+		{
+			name:        "tampered unreferenced blob",
+			tamper:      func(b blobs) ocispec.Descriptor { return b.unreferenced },
+			expectedErr: "does not match its digest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := oci.NewWithContext(t.Context(), dir)
+			require.NoError(t, err)
+			desc, err := pushTestManifest(t.Context(), store, []byte("layer"), "example:1.2.3")
+			require.NoError(t, err)
+			referrer, err := Attach(t.Context(), store, desc, "application/vnd.example.referrer", "text/plain", []byte("referrer"), nil)
+			require.NoError(t, err)
+			untagged, err := pushTestManifest(t.Context(), store, []byte("untagged"), "")
+			require.NoError(t, err)
+			unreferenced, err := oras.PushBytes(t.Context(), store, "text/plain", []byte("unreferenced"))
+			require.NoError(t, err)
+
+			var b blobs
+			manifest, err := FetchManifest(t.Context(), store, desc)
+			require.NoError(t, err)
+			b.layer = manifest.Layers[0]
+			b.referrerLayer, _, err = FetchArtifact(t.Context(), store, referrer)
+			require.NoError(t, err)
+			manifest, err = FetchManifest(t.Context(), store, untagged)
+			require.NoError(t, err)
+			b.untaggedLayer = manifest.Layers[0]
+			b.unreferenced = unreferenced
+
+			if tt.tamper != nil {
+				blob := tt.tamper(b)
+				blobPath := filepath.Join(dir, "blobs", blob.Digest.Algorithm().String(), blob.Digest.Encoded())
+				data, err := os.ReadFile(blobPath)
+				require.NoError(t, err)
+				data[0] ^= 0xff
+				require.NoError(t, os.WriteFile(blobPath, data, 0o644))
+			}
+
+			err = VerifyBlobs(t.Context(), os.DirFS(dir))
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_UpdateArchive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "test.kpm")
+	var desc ocispec.Descriptor
+	require.NoError(t, WriteArchive(t.Context(), archivePath, func(store *oci.Store) error {
+		var err error
+		desc, err = pushTestManifest(t.Context(), store, []byte("layer"), "example:1.2.3")
+		return err
+	}))
+
+	var referrer ocispec.Descriptor
+	require.NoError(t, UpdateArchive(t.Context(), archivePath, func(store *oci.Store) error {
+		var err error
+		referrer, err = Attach(t.Context(), store, desc, "application/vnd.example.referrer", "text/plain", []byte("referrer"), nil)
+		return err
+	}))
+
+	updated, err := OpenArchive(t.Context(), archivePath)
+	require.NoError(t, err)
+	resolved, err := ResolveManifest(t.Context(), updated, "")
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, resolved.Digest)
+	referrers, err := Referrers(t.Context(), updated, desc, "")
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	require.Equal(t, referrer.Digest, referrers[0].Digest)
+	require.NoError(t, VerifyArchive(t.Context(), archivePath))
+}
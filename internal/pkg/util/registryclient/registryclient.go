@@ -0,0 +1,79 @@
+// Package registryclient creates clients for image registries that
+// authenticate using the credentials from the local docker configuration.
+package registryclient
+
+import (
+	"fmt"
+
+	"github.com/distribution/reference"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// NewClient returns a registry client that authenticates using the
+// credentials from the local docker configuration.
+func NewClient() (*auth.Client, error) {
+	credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry credentials: %w", err)
+	}
+	return &auth.Client{
+		Client:     retry.DefaultClient,
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(credStore),
+	}, nil
+}
+
+// NewRepository returns a remote repository for the image reference ref that
+// authenticates using the credentials from the local docker configuration.
+func NewRepository(ref string, plainHTTP bool) (*remote.Repository, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return NewRepositoryWithClient(ref, client, plainHTTP)
+}
+
+// NewRepositoryWithClient returns a remote repository for the image reference
+// ref that uses client. The reference is parsed with ParseReference, and
+// Docker Hub repositories are accessed through the Docker Hub registry API
+// host, so the Registry of the repository's Reference is that host rather
+// than docker.io.
+func NewRepositoryWithClient(ref string, client remote.Client, plainHTTP bool) (*remote.Repository, error) {
+	parsed, err := ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Registry == dockerHubDomain {
+		parsed.Registry = dockerHubRegistry
+	}
+	return &remote.Repository{Reference: parsed, Client: client, PlainHTTP: plainHTTP}, nil
+}
+
+const (
+	// dockerHubDomain is the domain of Docker Hub image references.
+	dockerHubDomain = "docker.io"
+	// dockerHubRegistry is the host of the Docker Hub registry API, which is
+	// not the same as the domain of Docker Hub image references.
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// ParseReference parses the image reference ref like container runtimes do,
+// e.g. nginx:1 is expanded to docker.io/library/nginx:1. Unlike container
+// runtimes, it does not default the tag to latest, so that callers can tell
+// whether ref has a tag or digest; use ReferenceOrDefault of the result to
+// resolve it.
+func ParseReference(ref string) (registry.Reference, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return registry.Reference{}, fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	parsed, err := registry.ParseReference(named.String())
+	if err != nil {
+		return registry.Reference{}, fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,58 @@
+package registryclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewRepositoryWithClient(t *testing.T) {
+	tests := []struct {
+		name               string
+		ref                string
+		expectedImage      string
+		expectedRepository string
+		expectedErr        string
+	}{
+		{
+			name:               "docker hub short name",
+			ref:                "nginx",
+			expectedImage:      "docker.io/library/nginx",
+			expectedRepository: "registry-1.docker.io/library/nginx",
+		},
+		{
+			name:               "docker hub tag",
+			ref:                "org/image:1",
+			expectedImage:      "docker.io/org/image:1",
+			expectedRepository: "registry-1.docker.io/org/image:1",
+		},
+		{
+			name:               "other registry",
+			ref:                "localhost:5000/org/image@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expectedImage:      "localhost:5000/org/image@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expectedRepository: "localhost:5000/org/image@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:        "invalid",
+			ref:         "Org/Image",
+			expectedErr: `invalid image reference "Org/Image"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := ParseReference(tt.ref)
+			repo, repoErr := NewRepositoryWithClient(tt.ref, http.DefaultClient, true)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				require.ErrorContains(t, repoErr, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, repoErr)
+			require.Equal(t, tt.expectedImage, image.String())
+			require.Equal(t, tt.expectedRepository, repo.Reference.String())
+			require.True(t, repo.PlainHTTP)
+		})
+	}
+}
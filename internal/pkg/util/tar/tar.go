@@ -3,6 +3,7 @@ package tar
 import (
 	"archive/tar"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -22,6 +23,9 @@ func Extract(r io.Reader, dest string) error {
 		}
 
 		// the target location where the dir/file should be created
+		if !filepath.IsLocal(header.Name) {
+			return fmt.Errorf("invalid entry name %q: path must be local to the destination", header.Name)
+		}
 		target := filepath.Join(dest, header.Name)
 
 		// check the file type
@@ -37,7 +41,10 @@ func Extract(r io.Reader, dest string) error {
 
 		// if it's a file create it
 		case tar.TypeReg:
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,86 @@
+package tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Extract(t *testing.T) {
+	type entry struct {
+		name     string
+		typeflag byte
+		data     string
+	}
+	tests := []struct {
+		name          string
+		entries       []entry
+		expectedFiles map[string]string
+		expectedErr   string
+	}{
+		{
+			name: "nested files",
+			entries: []entry{
+				{name: "manifests/", typeflag: tar.TypeDir},
+				{name: "manifests/csv.yaml", typeflag: tar.TypeReg, data: "csv"},
+				{name: "metadata/nested/annotations.yaml", typeflag: tar.TypeReg, data: "annotations"},
+			},
+			expectedFiles: map[string]string{
+				"manifests/csv.yaml":               "csv",
+				"metadata/nested/annotations.yaml": "annotations",
+			},
+		},
+		{
+			name:        "parent directory",
+			entries:     []entry{{name: "../escaped.yaml", typeflag: tar.TypeReg, data: "escaped"}},
+			expectedErr: `invalid entry name "../escaped.yaml": path must be local to the destination`,
+		},
+		{
+			name:        "nested parent directory",
+			entries:     []entry{{name: "manifests/../../escaped.yaml", typeflag: tar.TypeReg, data: "escaped"}},
+			expectedErr: `invalid entry name "manifests/../../escaped.yaml": path must be local to the destination`,
+		},
+		{
+			name:        "absolute path",
+			entries:     []entry{{name: "/tmp/escaped.yaml", typeflag: tar.TypeReg, data: "escaped"}},
+			expectedErr: `invalid entry name "/tmp/escaped.yaml": path must be local to the destination`,
+		},
+		{
+			name:        "symlink",
+			entries:     []entry{{name: "link", typeflag: tar.TypeSymlink}},
+			expectedErr: "unsupported entry type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, e := range tt.entries {
+				require.NoError(t, tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0o644, Size: int64(len(e.data)), Linkname: "target"}))
+				_, err := tw.Write([]byte(e.data))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+
+			root := t.TempDir()
+			dest := filepath.Join(root, "dest")
+			require.NoError(t, os.Mkdir(dest, 0o755))
+			err := Extract(&buf, dest)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				require.NoFileExists(t, filepath.Join(root, "escaped.yaml"))
+				return
+			}
+			require.NoError(t, err)
+			for name, data := range tt.expectedFiles {
+				actual, err := os.ReadFile(filepath.Join(dest, name))
+				require.NoError(t, err)
+				require.Equal(t, data, string(actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package version
+
+import (
+	"runtime/debug"
+)
+
+// version can be set at build time with
+// -ldflags "-X github.com/operator-framework/kpm/internal/pkg/version.version=<version>".
+var version = ""
+
+// Version returns the version of kpm. Unless it was set at build time, it is
+// the module version (e.g. when installed with go install), the VCS revision,
+// or "devel".
+func Version() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return "devel-" + setting.Value[:min(len(setting.Value), 12)]
+		}
+	}
+	return "devel"
+}
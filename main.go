@@ -1,13 +1,22 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/operator-framework/kpm/internal/cli"
 )
 
 func main() {
 	if err := cli.Root("kpm").Execute(); err != nil {
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err != nil {
+				log.Print(exitErr.Err)
+			}
+			os.Exit(exitErr.Code)
+		}
 		log.Fatal(err)
 	}
 }